@@ -21,6 +21,42 @@ func main() {
 		os.Exit(1)
 	}
 
+	err = app.InitJournal()
+	if err != nil {
+		logger.Errorf("failed to init request journal with error [%s]", err.Error())
+		os.Exit(1)
+	}
+
+	err = app.LoadMocks()
+	if err != nil {
+		logger.Errorf("failed to load mocks with error [%s]", err.Error())
+		os.Exit(1)
+	}
+
+	err = app.LoadFallbacks()
+	if err != nil {
+		logger.Errorf("failed to load fallbacks with error [%s]", err.Error())
+		os.Exit(1)
+	}
+
+	err = app.LoadChaosPolicies()
+	if err != nil {
+		logger.Errorf("failed to load chaos policies with error [%s]", err.Error())
+		os.Exit(1)
+	}
+
+	err = app.LoadScenarios()
+	if err != nil {
+		logger.Errorf("failed to load scenarios with error [%s]", err.Error())
+		os.Exit(1)
+	}
+
+	err = app.LoadSpecs()
+	if err != nil {
+		logger.Errorf("failed to load openapi specs with error [%s]", err.Error())
+		os.Exit(1)
+	}
+
 	go func() {
 		logger.Info("starting mock app router on port 5081...")
 		err = http.ListenAndServe(":5081", app.NewRouter())
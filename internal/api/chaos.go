@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+)
+
+var validChaosActions = map[string]struct{}{
+	db.ChaosStatus: {},
+	db.ChaosDelay:  {},
+	db.ChaosFault:  {},
+}
+
+type ChaosPolicy struct {
+	GroupID int            `json:"group_id"`
+	Seed    int64          `json:"seed"`
+	Rules   []db.ChaosRule `json:"rules"`
+}
+
+func newChaosPolicy(dbPolicy db.ChaosPolicy) (ChaosPolicy, error) {
+	rules, err := dbPolicy.GetRules()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal chaos rules of group [%d]: [%s]", dbPolicy.GroupID, err)
+		return ChaosPolicy{}, err
+	}
+
+	return ChaosPolicy{
+		GroupID: dbPolicy.GroupID,
+		Seed:    dbPolicy.Seed,
+		Rules:   rules,
+	}, nil
+}
+
+// maxChaosProbabilityError is a float error allowed in total probability of rules
+const maxChaosProbabilityError = 1e-9
+
+type setChaosRQ struct {
+	Seed  int64          `json:"seed"`
+	Rules []db.ChaosRule `json:"rules"`
+}
+
+func (rq setChaosRQ) Validate() error {
+	total := 0.0
+	for i, rule := range rq.Rules {
+		if _, ok := validChaosActions[rule.Action]; !ok {
+			return fmt.Errorf("rule [%d] action is not valid", i)
+		}
+
+		if rule.Probability <= 0 || rule.Probability > 1 {
+			return fmt.Errorf("rule [%d] probability must be in (0, 1]", i)
+		}
+
+		total += rule.Probability
+		if total > 1+maxChaosProbabilityError {
+			return fmt.Errorf("rule [%d] makes total probability of rules greater than 1", i)
+		}
+
+		switch rule.Action {
+		case db.ChaosStatus:
+			if rule.Status <= 0 {
+				return fmt.Errorf("rule [%d] status not valid", i)
+			}
+		case db.ChaosDelay:
+			if rule.DelayMs <= 0 {
+				return fmt.Errorf("rule [%d] delay not valid", i)
+			}
+		case db.ChaosFault:
+			if _, ok := validFaults[rule.Fault]; !ok {
+				return fmt.Errorf("rule [%d] fault is not valid", i)
+			}
+		}
+	}
+
+	return nil
+}
+
+type getChaosRS struct {
+	baseRS
+	Chaos ChaosPolicy `json:"chaos"`
+}
+
+func getChaosHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("get chaos handler...")
+
+	rs := getChaosRS{}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	policy := db.ChaosPolicy{GroupID: groupID}
+	ok, err := policy.One()
+	if err != nil {
+		logger.Errorf("failed to get chaos policy with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		logger.Errorf("chaos policy of group [%d] does not exist", groupID)
+		rs.setError(myerrors.ErrChaosNotFound)
+		writeResponse(w, rs, http.StatusNotFound)
+		return
+	}
+
+	rs.Chaos, err = newChaosPolicy(policy)
+	if err != nil {
+		logger.Errorf("failed to convert chaos policy with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func setChaosHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("set chaos handler...")
+
+	rs := baseRS{}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	rq := setChaosRQ{}
+	err := json.NewDecoder(r.Body).Decode(&rq)
+	if err != nil {
+		logger.Errorf("failed to decode request with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	err = rq.Validate()
+	if err != nil {
+		logger.Errorf("request is not valid: [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	rules, err := json.Marshal(rq.Rules)
+	if err != nil {
+		logger.Errorf("failed to marshal chaos rules with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	policy := db.ChaosPolicy{
+		GroupID: groupID,
+		Seed:    rq.Seed,
+		Rules:   rules,
+	}
+	err = policy.Save()
+	if err != nil {
+		logger.Errorf("failed to save chaos policy with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	err = app.SetChaosPolicy(policy)
+	if err != nil {
+		logger.Errorf("failed to apply chaos policy with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func deleteChaosHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("delete chaos handler...")
+
+	rs := baseRS{}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	err := db.DeleteChaosPolicy(groupID)
+	if err != nil {
+		logger.Errorf("failed to delete chaos policy with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	app.RemoveChaosPolicy(groupID)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/maptool"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+)
+
+var validFallbackModes = map[string]struct{}{
+	db.FallbackNotFound:    {},
+	db.FallbackCustom:      {},
+	db.FallbackProxy:       {},
+	db.FallbackDiagnostics: {},
+}
+
+type Fallback struct {
+	GroupID  int                     `json:"group_id"`
+	Mode     string                  `json:"mode"`
+	Status   int                     `json:"status,omitempty"`
+	Headers  []maptool.SortedJSONMap `json:"headers,omitempty"`
+	Body     string                  `json:"body,omitempty"`
+	ProxyURL string                  `json:"proxy_url,omitempty"`
+	Proxy    *ProxyOptions           `json:"proxy,omitempty"`
+}
+
+func newFallback(dbFallback db.Fallback) (Fallback, error) {
+	headers, err := dbFallback.GetHeaders()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal headers for fallback of group [%d]: [%s]", dbFallback.GroupID, err)
+		return Fallback{}, err
+	}
+
+	proxy, err := dbFallback.GetProxy()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal proxy options for fallback of group [%d]: [%s]", dbFallback.GroupID, err)
+		return Fallback{}, err
+	}
+
+	return Fallback{
+		GroupID:  dbFallback.GroupID,
+		Mode:     dbFallback.Mode,
+		Status:   dbFallback.Status,
+		Headers:  maptool.SortJSONMap(headers),
+		Body:     dbFallback.Body,
+		ProxyURL: dbFallback.ProxyURL,
+		Proxy:    newProxyOptions(proxy),
+	}, nil
+}
+
+type setFallbackRQ struct {
+	Mode     string                  `json:"mode"`
+	Status   int                     `json:"status"`
+	Headers  []maptool.SortedJSONMap `json:"headers"`
+	Body     string                  `json:"body"`
+	ProxyURL string                  `json:"proxy_url"`
+	Proxy    *ProxyOptions           `json:"proxy"`
+}
+
+func (rq setFallbackRQ) Validate() error {
+	if _, ok := validFallbackModes[rq.Mode]; !ok {
+		return errors.New("mode is not valid")
+	}
+
+	switch rq.Mode {
+	case db.FallbackCustom:
+		if rq.Status <= 0 {
+			return errors.New("status not valid")
+		}
+
+		for _, h := range rq.Headers {
+			if stringtool.Empty(h.Key) {
+				return errors.New("header is empty")
+			}
+		}
+	case db.FallbackProxy:
+		return validateProxy(rq.ProxyURL, rq.Proxy)
+	}
+
+	if rq.Proxy != nil {
+		return errors.New("proxy is allowed only for proxy mode")
+	}
+
+	return nil
+}
+
+type getFallbackRS struct {
+	baseRS
+	Fallback Fallback `json:"fallback"`
+}
+
+// fallbackGroupID gets group id from path, global fallback routes have no group id
+func fallbackGroupID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	if _, ok := mux.Vars(r)[groupIDKey]; !ok {
+		return db.GlobalFallbackGroupID, true
+	}
+
+	return getExistingGroupID(w, r)
+}
+
+func getFallbackHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("get fallback handler...")
+
+	rs := getFallbackRS{}
+
+	groupID, ok := fallbackGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	fallback := db.Fallback{GroupID: groupID}
+	ok, err := fallback.One()
+	if err != nil {
+		logger.Errorf("failed to get fallback with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		logger.Errorf("fallback of group [%d] does not exist", groupID)
+		rs.setError(myerrors.ErrFallbackNotFound)
+		writeResponse(w, rs, http.StatusNotFound)
+		return
+	}
+
+	rs.Fallback, err = newFallback(fallback)
+	if err != nil {
+		logger.Errorf("failed to convert fallback with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func setFallbackHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("set fallback handler...")
+
+	rs := baseRS{}
+
+	groupID, ok := fallbackGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	rq := setFallbackRQ{}
+	err := json.NewDecoder(r.Body).Decode(&rq)
+	if err != nil {
+		logger.Errorf("failed to decode request with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	err = rq.Validate()
+	if err != nil {
+		logger.Errorf("request is not valid: [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	headers, err := json.Marshal(maptool.UnsortJSONMap(rq.Headers))
+	if err != nil {
+		logger.Errorf("failed to marshal headers with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	proxy, err := rq.Proxy.marshal()
+	if err != nil {
+		logger.Errorf("failed to marshal proxy with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	fallback := db.Fallback{
+		GroupID:  groupID,
+		Mode:     rq.Mode,
+		Status:   rq.Status,
+		Headers:  headers,
+		Body:     rq.Body,
+		ProxyURL: rq.ProxyURL,
+		Proxy:    proxy,
+	}
+	err = fallback.Save()
+	if err != nil {
+		logger.Errorf("failed to save fallback with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	app.SetFallback(fallback)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func deleteFallbackHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("delete fallback handler...")
+
+	rs := baseRS{}
+
+	groupID, ok := fallbackGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	err := db.DeleteFallback(groupID)
+	if err != nil {
+		logger.Errorf("failed to delete fallback with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	app.RemoveFallback(groupID)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
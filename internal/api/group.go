@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/mmiloslav/mock/internal/app"
 	"github.com/mmiloslav/mock/internal/db"
 	"github.com/mmiloslav/mock/internal/myerrors"
 	"github.com/mmiloslav/mock/internal/mylog"
@@ -181,6 +182,47 @@ func deleteGroupHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	app.RemoveGroup(groupID)
+	app.RemoveFallback(groupID)
+	app.RemoveChaosPolicy(groupID)
+	app.RemoveSpec(groupID)
+	if recording := app.GetRecording(); recording.Active && recording.GroupID == groupID {
+		_, err = app.StopRecording()
+		if err != nil {
+			logger.Errorf("failed to activate recorded mocks with error [%s]", err.Error())
+		}
+	}
+
 	rs.setSuccess()
 	writeResponse(w, rs, http.StatusOK)
 }
+
+// getExistingGroupID gets group id from path and checks group exists, writes error response otherwise
+func getExistingGroupID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	rs := baseRS{}
+
+	groupID, err := getID(r, groupIDKey)
+	if err != nil {
+		logger.Errorf("failed to get group id with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return 0, false
+	}
+
+	ok, err := db.GroupExistsByID(groupID)
+	if err != nil {
+		logger.Errorf("failed to check if group exists with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return 0, false
+	}
+	if !ok {
+		logger.Errorf("group with id [%d] does not exist", groupID)
+		rs.setError(myerrors.ErrGroupNotFound)
+		writeResponse(w, rs, http.StatusNotFound)
+		return 0, false
+	}
+
+	return groupID, true
+}
@@ -0,0 +1,368 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/hartool"
+	"github.com/mmiloslav/mock/pkg/jsontool"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+)
+
+const (
+	formatHAR   = "har"
+	mockAppPort = "5081"
+)
+
+// mockAppURL gets base url of mock port on the host api is called on
+func mockAppURL(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+
+	return "http://" + net.JoinHostPort(host, mockAppPort)
+}
+
+// newHAREntries converts mock to har entries, one per response of sequence or variants
+func newHAREntries(m db.Mock, baseURL string) ([]hartool.Entry, error) {
+	query, err := m.GetRqQueryParams()
+	if err != nil {
+		return nil, err
+	}
+
+	rqHeaders, err := m.GetRqHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := m.GetRsSequence()
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := m.GetRsVariants()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range variants {
+		sequence = append(sequence, v.SequenceResponse)
+	}
+
+	if len(sequence) == 0 {
+		headers, err := m.GetRsHeaders()
+		if err != nil {
+			return nil, err
+		}
+
+		sequence = []db.SequenceResponse{{Status: m.RsStatus, Headers: headers, Body: m.RsBody}}
+	}
+
+	rq := hartool.Request{
+		Method:      m.RqMethod,
+		URL:         baseURL + m.RqPath,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []hartool.NameValue{},
+		Headers:     []hartool.NameValue{},
+		QueryString: hartool.NewNameValues(query),
+		HeadersSize: -1,
+		BodySize:    len(m.RqBody),
+	}
+
+	if len(query) > 0 {
+		rq.URL += "?" + url.Values(query).Encode()
+	}
+
+	for _, h := range rqHeaders {
+		if h.Match == db.HeaderMatchEquals {
+			rq.Headers = append(rq.Headers, hartool.NameValue{Name: h.Name, Value: h.Value})
+		}
+	}
+
+	if !stringtool.Empty(m.RqBody) {
+		rq.PostData = &hartool.PostData{MimeType: mimeType(m.RqBody), Text: m.RqBody}
+	}
+
+	entries := make([]hartool.Entry, 0, len(sequence))
+	for _, rs := range sequence {
+		contentType := http.Header(rs.Headers).Get("Content-Type")
+		if contentType == "" {
+			contentType = mimeType(rs.Body)
+		}
+
+		entries = append(entries, hartool.Entry{
+			StartedDateTime: m.CreatedAt,
+			Request:         rq,
+			Response: hartool.Response{
+				Status:      rs.Status,
+				StatusText:  http.StatusText(rs.Status),
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []hartool.NameValue{},
+				Headers:     hartool.NewNameValues(rs.Headers),
+				Content:     hartool.Content{Size: len(rs.Body), MimeType: contentType, Text: rs.Body},
+				HeadersSize: -1,
+				BodySize:    len(rs.Body),
+			},
+			Comment: m.Name,
+		})
+	}
+
+	return entries, nil
+}
+
+// newObservedHAREntry converts journal entry to har entry, response body is not journaled
+func newObservedHAREntry(e app.JournalEntry, baseURL string) hartool.Entry {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		u = &url.URL{Path: e.Path}
+	}
+
+	rq := hartool.Request{
+		Method:      e.Method,
+		URL:         baseURL + e.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []hartool.NameValue{},
+		Headers:     hartool.NewNameValues(e.Headers),
+		QueryString: hartool.NewNameValues(u.Query()),
+		HeadersSize: -1,
+		BodySize:    len(e.Body),
+	}
+
+	if !stringtool.Empty(e.Body) {
+		rq.PostData = &hartool.PostData{MimeType: http.Header(e.Headers).Get("Content-Type"), Text: e.Body}
+	}
+
+	return hartool.Entry{
+		StartedDateTime: e.Time,
+		Time:            float64(e.LatencyMs),
+		Request:         rq,
+		Response: hartool.Response{
+			Status:      e.Status,
+			StatusText:  http.StatusText(e.Status),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []hartool.NameValue{},
+			Headers:     []hartool.NameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: hartool.Timings{Wait: float64(e.LatencyMs)},
+		Comment: fmt.Sprintf("observed request [%s]", e.RequestID),
+	}
+}
+
+// newHARMock converts har entry to mock of group, ok is false for entries which cannot be mocked
+func newHARMock(e hartool.Entry, groupID int) (db.Mock, bool, error) {
+	method := strings.ToUpper(e.Request.Method)
+	if _, ok := validMethods[method]; !ok || e.Response.Status <= 0 {
+		return db.Mock{}, false, nil
+	}
+
+	u, err := url.Parse(e.Request.URL)
+	if err != nil {
+		return db.Mock{}, false, err
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	body := ""
+	if e.Request.PostData != nil && method != http.MethodGet {
+		body = e.Request.PostData.Text
+	}
+
+	rsBody, err := e.Response.Content.Body()
+	if err != nil {
+		return db.Mock{}, false, err
+	}
+
+	rsHeaders := map[string][]string{}
+	for _, h := range e.Response.Headers {
+		name := http.CanonicalHeaderKey(h.Name)
+		if _, ok := skippedImportHeaders[name]; ok || strings.HasPrefix(name, ":") {
+			continue
+		}
+
+		rsHeaders[name] = append(rsHeaders[name], h.Value)
+	}
+
+	m := db.Mock{
+		Name:        app.MockName(method, path, u.Query(), body),
+		Active:      true,
+		GroupID:     groupID,
+		RqMethod:    method,
+		RqPath:      path,
+		RqPathMatch: db.PathMatchExact,
+		RqBody:      body,
+		RsStatus:    e.Response.Status,
+		RsBody:      rsBody,
+	}
+
+	if body != "" && jsontool.Valid(body) {
+		m.RqBodyMatch = db.BodyMatchJSON
+	}
+
+	if query := u.Query(); len(query) > 0 {
+		m.RqQueryParams, err = json.Marshal(query)
+		if err != nil {
+			return db.Mock{}, false, err
+		}
+	}
+
+	m.RsHeaders, err = json.Marshal(rsHeaders)
+	if err != nil {
+		return db.Mock{}, false, err
+	}
+
+	return m, true, nil
+}
+
+// mimeType guesses mime type of body for har content
+func mimeType(body string) string {
+	if jsontool.Valid(body) {
+		return "application/json"
+	}
+
+	return "text/plain"
+}
+
+func exportGroupHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("export group handler...")
+
+	rs := baseRS{}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format != formatHAR {
+		logger.Errorf("export format [%s] is not supported", format)
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	mocks, err := db.GetGroupMocks(groupID)
+	if err != nil {
+		logger.Errorf("failed to get group mocks with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	baseURL := mockAppURL(r)
+	har := hartool.HAR{Log: hartool.Log{
+		Version: hartool.Version,
+		Creator: hartool.Creator{Name: "mock", Version: hartool.Version},
+		Entries: []hartool.Entry{},
+	}}
+
+	for _, m := range mocks {
+		if m.GetPathMatch() == db.PathMatchRegex || m.GetRsType() == db.RsTypeProxy {
+			logger.Infof("skipping mock [%d] which has no static request or response", m.ID)
+			continue
+		}
+
+		entries, err := newHAREntries(m, baseURL)
+		if err != nil {
+			logger.Errorf("failed to convert mock [%d] to har with error [%s]", m.ID, err.Error())
+			rs.setError(myerrors.ErrInternal)
+			writeResponse(w, rs, http.StatusInternalServerError)
+			return
+		}
+
+		har.Log.Entries = append(har.Log.Entries, entries...)
+	}
+
+	for _, e := range app.GetJournal(app.JournalFilter{GroupID: groupID}) {
+		har.Log.Entries = append(har.Log.Entries, newObservedHAREntry(e, baseURL))
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"group-%d.har\"", groupID))
+	writeResponse(w, har, http.StatusOK)
+}
+
+func importGroupHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("import group handler...")
+
+	rs := importGroupRS{IDs: []int{}}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format != formatHAR {
+		logger.Errorf("import format [%s] is not supported", format)
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	har, err := hartool.Decode(r.Body)
+	if err != nil {
+		logger.Errorf("failed to decode har with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	for i, e := range har.Log.Entries {
+		mock, ok, err := newHARMock(e, groupID)
+		if err != nil {
+			logger.Errorf("failed to convert har entry [%d] with error [%s]", i, err.Error())
+			rs.setError(myerrors.ErrBadRequest)
+			writeResponse(w, rs, http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			rs.Skipped++
+			continue
+		}
+
+		ok, err = db.MockExists(mock.Name, groupID)
+		if err != nil {
+			logger.Errorf("failed to check if mock exists with error [%s]", err.Error())
+			rs.setError(myerrors.ErrInternal)
+			writeResponse(w, rs, http.StatusInternalServerError)
+			return
+		}
+		if ok {
+			rs.Skipped++
+			continue
+		}
+
+		err = mock.Create()
+		if err != nil {
+			logger.Errorf("failed to create mock with error [%s]", err.Error())
+			rs.setError(myerrors.ErrInternal)
+			writeResponse(w, rs, http.StatusInternalServerError)
+			return
+		}
+
+		err = app.UpsertMock(mock)
+		if err != nil {
+			logger.Errorf("failed to add mock [%d] to index with error [%s]", mock.ID, err.Error())
+		}
+
+		rs.IDs = append(rs.IDs, mock.ID)
+	}
+
+	logger.Infof("imported [%d] mocks into group [%d], skipped [%d] entries", len(rs.IDs), groupID, rs.Skipped)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusCreated)
+}
@@ -0,0 +1,18 @@
+package api
+
+// skippedImportHeaders are response headers of imported traffic which do not apply to mock response,
+// body is stored decoded
+var skippedImportHeaders = map[string]struct{}{
+	"Content-Length":    {},
+	"Content-Encoding":  {},
+	"Transfer-Encoding": {},
+	"Connection":        {},
+	"Keep-Alive":        {},
+	"Date":              {},
+}
+
+type importGroupRS struct {
+	baseRS
+	IDs     []int `json:"ids"`
+	Skipped int   `json:"skipped"`
+}
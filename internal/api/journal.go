@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+)
+
+type getRequestsRS struct {
+	baseRS
+	Requests []app.JournalEntry `json:"requests"`
+}
+
+// newJournalFilter parses journal filter from query, time window bounds are RFC3339
+func newJournalFilter(query url.Values) (app.JournalFilter, error) {
+	f := app.JournalFilter{Path: query.Get("path")}
+
+	var err error
+	for key, dst := range map[string]*int{"mock_id": &f.MockID, "group_id": &f.GroupID, "status": &f.Status} {
+		if val := query.Get(key); val != "" {
+			*dst, err = strconv.Atoi(val)
+			if err != nil {
+				return app.JournalFilter{}, err
+			}
+		}
+	}
+
+	for key, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if val := query.Get(key); val != "" {
+			*dst, err = time.Parse(time.RFC3339, val)
+			if err != nil {
+				return app.JournalFilter{}, err
+			}
+		}
+	}
+
+	if val := query.Get("unmatched"); val != "" {
+		f.Unmatched, err = strconv.ParseBool(val)
+		if err != nil {
+			return app.JournalFilter{}, err
+		}
+	}
+
+	return f, nil
+}
+
+func getRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("get requests handler...")
+
+	rs := getRequestsRS{}
+
+	filter, err := newJournalFilter(r.URL.Query())
+	if err != nil {
+		logger.Errorf("failed to parse requests filter with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	rs.Requests = app.GetJournal(filter)
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func deleteRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("delete requests handler...")
+
+	rs := baseRS{}
+
+	app.ClearJournal()
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
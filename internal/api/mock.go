@@ -3,18 +3,72 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 
+	"github.com/mmiloslav/mock/internal/app"
 	"github.com/mmiloslav/mock/internal/db"
 	"github.com/mmiloslav/mock/internal/myerrors"
 	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/jsontool"
 	"github.com/mmiloslav/mock/pkg/maptool"
+	"github.com/mmiloslav/mock/pkg/pathtool"
 	"github.com/mmiloslav/mock/pkg/stringtool"
+	"github.com/mmiloslav/mock/pkg/templatetool"
 )
 
 const mockIDKey = "mock_id"
 
+var errPathRegexNotValid = errors.New("rq path regex is not valid")
+
+var validPathMatches = map[string]struct{}{
+	db.PathMatchExact:    {},
+	db.PathMatchTemplate: {},
+	db.PathMatchRegex:    {},
+}
+
+var validBodyMatches = map[string]struct{}{
+	db.BodyMatchRaw:          {},
+	db.BodyMatchJSON:         {},
+	db.BodyMatchJSONContains: {},
+}
+
+var validHeaderMatches = map[string]struct{}{
+	db.HeaderMatchEquals:   {},
+	db.HeaderMatchContains: {},
+	db.HeaderMatchRegex:    {},
+	db.HeaderMatchPresent:  {},
+	db.HeaderMatchAbsent:   {},
+}
+
+var validQueryMatches = map[string]struct{}{
+	db.QueryMatchExact:  {},
+	db.QueryMatchSubset: {},
+}
+
+var validQueryRules = map[string]struct{}{
+	db.QueryRuleRegex:    {},
+	db.QueryRuleWildcard: {},
+	db.QueryRulePresent:  {},
+	db.QueryRuleAbsent:   {},
+}
+
+var validFaults = map[string]struct{}{
+	db.FaultConnectionReset:  {},
+	db.FaultEmptyReply:       {},
+	db.FaultMalformedChunked: {},
+	db.FaultGarbage:          {},
+	db.FaultTruncated:        {},
+}
+
+var validSequenceModes = map[string]struct{}{
+	db.SequenceLoop:        {},
+	db.SequenceStick:       {},
+	db.SequenceFallThrough: {},
+}
+
 var validMethods = map[string]struct{}{
 	http.MethodGet:     {},
 	http.MethodHead:    {},
@@ -59,20 +113,135 @@ func getMocksHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 type Mock struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	Active bool   `json:"active"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Active   bool   `json:"active"`
+	Priority int    `json:"priority"`
+
+	// SCENARIO
+	ScenarioName          string `json:"scenario_name,omitempty"`
+	ScenarioRequiredState string `json:"scenario_required_state,omitempty"`
+	ScenarioNewState      string `json:"scenario_new_state,omitempty"`
 
 	// RQ
-	RqMethod      string                  `json:"rq_method"`
-	RqPath        string                  `json:"rq_path"`
-	RqBody        string                  `json:"rq_body,omitempty"`
-	RqQueryParams []maptool.SortedJSONMap `json:"rq_query_params,omitempty"`
+	RqMethod         string                  `json:"rq_method"`
+	RqPath           string                  `json:"rq_path"`
+	RqPathMatch      string                  `json:"rq_path_match"`
+	RqPathRegex      string                  `json:"rq_path_regex,omitempty"`
+	RqBody           string                  `json:"rq_body,omitempty"`
+	RqBodyMatch      string                  `json:"rq_body_match"`
+	RqBodyPredicates []string                `json:"rq_body_predicates,omitempty"`
+	RqQueryParams    []maptool.SortedJSONMap `json:"rq_query_params,omitempty"`
+	RqQueryMatch     string                  `json:"rq_query_match"`
+	RqQueryRules     []db.QueryRule          `json:"rq_query_rules,omitempty"`
+	RqHeaders        []db.HeaderMatcher      `json:"rq_headers,omitempty"`
 
 	// RS
-	RsStatus  int                     `json:"rs_status"`
-	RsHeaders []maptool.SortedJSONMap `json:"rs_headers,omitempty"`
-	RsBody    string                  `json:"rs_body,omitempty"`
+	RsType     string                  `json:"rs_type"`
+	RsStatus   int                     `json:"rs_status"`
+	RsHeaders  []maptool.SortedJSONMap `json:"rs_headers,omitempty"`
+	RsBody     string                  `json:"rs_body,omitempty"`
+	RsTemplate bool                    `json:"rs_template"`
+
+	// RS PROXY
+	RsProxyURL string        `json:"rs_proxy_url,omitempty"`
+	RsProxy    *ProxyOptions `json:"rs_proxy,omitempty"`
+
+	// RS SEQUENCE
+	RsSequence     []SequenceResponse `json:"rs_sequence,omitempty"`
+	RsSequenceMode string             `json:"rs_sequence_mode,omitempty"`
+
+	// RS VARIANTS
+	RsVariants []ResponseVariant `json:"rs_variants,omitempty"`
+
+	// RS DELAY
+	RsDelayDistribution string  `json:"rs_delay_distribution"`
+	RsDelayMs           int     `json:"rs_delay_ms,omitempty"`
+	RsDelayMinMs        int     `json:"rs_delay_min_ms,omitempty"`
+	RsDelayMaxMs        int     `json:"rs_delay_max_ms,omitempty"`
+	RsDelayMedianMs     int     `json:"rs_delay_median_ms,omitempty"`
+	RsDelaySigma        float64 `json:"rs_delay_sigma,omitempty"`
+
+	// RS FAULT
+	RsFault      string `json:"rs_fault,omitempty"`
+	RsFaultBytes int    `json:"rs_fault_bytes,omitempty"`
+}
+
+type SequenceResponse struct {
+	Status  int                     `json:"status"`
+	Headers []maptool.SortedJSONMap `json:"headers,omitempty"`
+	Body    string                  `json:"body,omitempty"`
+}
+
+func newSequence(dbSequence []db.SequenceResponse) []SequenceResponse {
+	if len(dbSequence) == 0 {
+		return nil
+	}
+
+	sequence := make([]SequenceResponse, 0, len(dbSequence))
+	for _, rs := range dbSequence {
+		sequence = append(sequence, SequenceResponse{
+			Status:  rs.Status,
+			Headers: maptool.SortJSONMap(rs.Headers),
+			Body:    rs.Body,
+		})
+	}
+
+	return sequence
+}
+
+func newDBSequence(sequence []SequenceResponse) []db.SequenceResponse {
+	dbSequence := make([]db.SequenceResponse, 0, len(sequence))
+	for _, rs := range sequence {
+		dbSequence = append(dbSequence, db.SequenceResponse{
+			Status:  rs.Status,
+			Headers: maptool.UnsortJSONMap(rs.Headers),
+			Body:    rs.Body,
+		})
+	}
+
+	return dbSequence
+}
+
+type ResponseVariant struct {
+	Weight int `json:"weight"`
+	SequenceResponse
+}
+
+func newVariants(dbVariants []db.ResponseVariant) []ResponseVariant {
+	if len(dbVariants) == 0 {
+		return nil
+	}
+
+	variants := make([]ResponseVariant, 0, len(dbVariants))
+	for _, v := range dbVariants {
+		variants = append(variants, ResponseVariant{
+			Weight: v.Weight,
+			SequenceResponse: SequenceResponse{
+				Status:  v.Status,
+				Headers: maptool.SortJSONMap(v.Headers),
+				Body:    v.Body,
+			},
+		})
+	}
+
+	return variants
+}
+
+func newDBVariants(variants []ResponseVariant) []db.ResponseVariant {
+	dbVariants := make([]db.ResponseVariant, 0, len(variants))
+	for _, v := range variants {
+		dbVariants = append(dbVariants, db.ResponseVariant{
+			Weight: v.Weight,
+			SequenceResponse: db.SequenceResponse{
+				Status:  v.Status,
+				Headers: maptool.UnsortJSONMap(v.Headers),
+				Body:    v.Body,
+			},
+		})
+	}
+
+	return dbVariants
 }
 
 func newMocks(dbMocks []db.Mock) ([]Mock, error) {
@@ -108,34 +277,151 @@ func newMock(dbMock db.Mock) (Mock, error) {
 		}
 	}
 
+	bodyPredicates, err := dbMock.GetRqBodyPredicates()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal body predicates for mock [%d]: [%s]", dbMock.ID, err)
+		return Mock{}, err
+	}
+
+	queryRules, err := dbMock.GetRqQueryRules()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal query rules for mock [%d]: [%s]", dbMock.ID, err)
+		return Mock{}, err
+	}
+
+	rqHeaders, err := dbMock.GetRqHeaders()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal request headers for mock [%d]: [%s]", dbMock.ID, err)
+		return Mock{}, err
+	}
+
+	sequence, err := dbMock.GetRsSequence()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal response sequence for mock [%d]: [%s]", dbMock.ID, err)
+		return Mock{}, err
+	}
+
+	variants, err := dbMock.GetRsVariants()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal response variants for mock [%d]: [%s]", dbMock.ID, err)
+		return Mock{}, err
+	}
+
+	proxy, err := dbMock.GetRsProxy()
+	if err != nil {
+		mylog.Logger.Errorf("failed to unmarshal proxy options for mock [%d]: [%s]", dbMock.ID, err)
+		return Mock{}, err
+	}
+
+	sequenceMode := ""
+	if len(sequence) > 0 {
+		sequenceMode = dbMock.GetSequenceMode()
+	}
+
 	return Mock{
-		ID:            dbMock.ID,
-		Name:          dbMock.Name,
-		Active:        dbMock.Active,
-		RqMethod:      dbMock.RqMethod,
-		RqPath:        dbMock.RqPath,
-		RqBody:        dbMock.RqBody,
-		RqQueryParams: maptool.SortJSONMap(queryParams),
-		RsStatus:      dbMock.RsStatus,
-		RsHeaders:     maptool.SortJSONMap(rsHeaders),
-		RsBody:        dbMock.RsBody,
+		ID:       dbMock.ID,
+		Name:     dbMock.Name,
+		Active:   dbMock.Active,
+		Priority: dbMock.Priority,
+
+		ScenarioName:          dbMock.ScenarioName,
+		ScenarioRequiredState: dbMock.ScenarioRequiredState,
+		ScenarioNewState:      dbMock.ScenarioNewState,
+
+		RqMethod:         dbMock.RqMethod,
+		RqPath:           dbMock.RqPath,
+		RqPathMatch:      dbMock.GetPathMatch(),
+		RqPathRegex:      dbMock.RqPathRegex,
+		RqBody:           dbMock.RqBody,
+		RqBodyMatch:      dbMock.GetBodyMatch(),
+		RqBodyPredicates: bodyPredicates,
+		RqQueryParams:    maptool.SortJSONMap(queryParams),
+		RqQueryMatch:     dbMock.GetQueryMatch(),
+		RqQueryRules:     queryRules,
+		RqHeaders:        rqHeaders,
+		RsType:           dbMock.GetRsType(),
+		RsStatus:         dbMock.RsStatus,
+		RsHeaders:        maptool.SortJSONMap(rsHeaders),
+		RsBody:           dbMock.RsBody,
+		RsTemplate:       dbMock.RsTemplate,
+
+		RsProxyURL: dbMock.RsProxyURL,
+		RsProxy:    newProxyOptions(proxy),
+
+		RsSequence:     newSequence(sequence),
+		RsSequenceMode: sequenceMode,
+
+		RsVariants: newVariants(variants),
+
+		RsDelayDistribution: dbMock.GetDelayDistribution(),
+		RsDelayMs:           dbMock.RsDelayMs,
+		RsDelayMinMs:        dbMock.RsDelayMinMs,
+		RsDelayMaxMs:        dbMock.RsDelayMaxMs,
+		RsDelayMedianMs:     dbMock.RsDelayMedianMs,
+		RsDelaySigma:        dbMock.RsDelaySigma,
+
+		RsFault:      dbMock.RsFault,
+		RsFaultBytes: dbMock.RsFaultBytes,
 	}, nil
 }
 
+// requestPattern is a request matcher shared by mocks & verification
+type requestPattern struct {
+	RqMethod         string                  `json:"rq_method"`
+	RqPath           string                  `json:"rq_path"`
+	RqPathMatch      string                  `json:"rq_path_match"`
+	RqPathRegex      string                  `json:"rq_path_regex"`
+	RqBody           string                  `json:"rq_body"`
+	RqBodyMatch      string                  `json:"rq_body_match"`
+	RqBodyPredicates []string                `json:"rq_body_predicates"`
+	RqQueryParams    []maptool.SortedJSONMap `json:"rq_query_params"`
+	RqQueryMatch     string                  `json:"rq_query_match"`
+	RqQueryRules     []db.QueryRule          `json:"rq_query_rules"`
+	RqHeaders        []db.HeaderMatcher      `json:"rq_headers"`
+}
+
 type createMockRQ struct {
-	Name    string `json:"name"`
-	GroupID int    `json:"group_id"`
+	Name     string `json:"name"`
+	GroupID  int    `json:"group_id"`
+	Priority int    `json:"priority"`
+
+	// SCENARIO
+	ScenarioName          string `json:"scenario_name"`
+	ScenarioRequiredState string `json:"scenario_required_state"`
+	ScenarioNewState      string `json:"scenario_new_state"`
 
 	//RQ
-	RqMethod      string                  `json:"rq_method"`
-	RqPath        string                  `json:"rq_path"`
-	RqBody        string                  `json:"rq_body"`
-	RqQueryParams []maptool.SortedJSONMap `json:"rq_query_params"`
+	requestPattern
 
 	//RS
-	RsStatus  int                     `json:"rs_status"`
-	RsHeaders []maptool.SortedJSONMap `json:"rs_headers"`
-	RsBody    string                  `json:"rs_body"`
+	RsType     string                  `json:"rs_type"`
+	RsStatus   int                     `json:"rs_status"`
+	RsHeaders  []maptool.SortedJSONMap `json:"rs_headers"`
+	RsBody     string                  `json:"rs_body"`
+	RsTemplate bool                    `json:"rs_template"`
+
+	// RS PROXY
+	RsProxyURL string        `json:"rs_proxy_url"`
+	RsProxy    *ProxyOptions `json:"rs_proxy"`
+
+	// RS SEQUENCE
+	RsSequence     []SequenceResponse `json:"rs_sequence"`
+	RsSequenceMode string             `json:"rs_sequence_mode"`
+
+	// RS VARIANTS
+	RsVariants []ResponseVariant `json:"rs_variants"`
+
+	// RS DELAY
+	RsDelayDistribution string  `json:"rs_delay_distribution"`
+	RsDelayMs           int     `json:"rs_delay_ms"`
+	RsDelayMinMs        int     `json:"rs_delay_min_ms"`
+	RsDelayMaxMs        int     `json:"rs_delay_max_ms"`
+	RsDelayMedianMs     int     `json:"rs_delay_median_ms"`
+	RsDelaySigma        float64 `json:"rs_delay_sigma"`
+
+	// RS FAULT
+	RsFault      string `json:"rs_fault"`
+	RsFaultBytes int    `json:"rs_fault_bytes"`
 }
 
 func (rq createMockRQ) Validate() error {
@@ -147,7 +433,55 @@ func (rq createMockRQ) Validate() error {
 		return errors.New("groupID not valid")
 	}
 
-	// RQ
+	if stringtool.Empty(rq.ScenarioName) && (!stringtool.Empty(rq.ScenarioRequiredState) || !stringtool.Empty(rq.ScenarioNewState)) {
+		return errors.New("scenario name is empty")
+	}
+
+	err := rq.requestPattern.Validate()
+	if err != nil {
+		return err
+	}
+
+	//RS
+	if _, ok := validRsTypes[rq.RsType]; !ok && !stringtool.Empty(rq.RsType) {
+		return errors.New("rs type is not valid")
+	}
+
+	if rq.RsType == db.RsTypeProxy {
+		err = rq.validateProxy()
+		if err != nil {
+			return err
+		}
+	} else if !stringtool.Empty(rq.RsProxyURL) || rq.RsProxy != nil {
+		return errors.New("rs proxy is allowed only for proxy rs type")
+	}
+
+	err = rq.validateSequence()
+	if err != nil {
+		return err
+	}
+
+	err = rq.validateVariants()
+	if err != nil {
+		return err
+	}
+
+	if rq.RsType != db.RsTypeProxy && len(rq.RsSequence) == 0 && len(rq.RsVariants) == 0 {
+		err = validateResponse(rq.RsStatus, rq.RsHeaders, rq.RsBody, rq.RsTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = rq.validateDelay()
+	if err != nil {
+		return err
+	}
+
+	return rq.validateFault()
+}
+
+func (rq requestPattern) Validate() error {
 	if stringtool.Empty(rq.RqMethod) {
 		return errors.New("rq method is empty")
 	}
@@ -156,10 +490,31 @@ func (rq createMockRQ) Validate() error {
 		return errors.New("cannot add rq body for GET method")
 	}
 
+	if rq.RqMethod == http.MethodGet && len(rq.RqBodyPredicates) > 0 {
+		return errors.New("cannot add rq body predicates for GET method")
+	}
+
+	for _, p := range rq.RqBodyPredicates {
+		_, err := jsontool.ParsePredicate(p)
+		if err != nil {
+			return err
+		}
+	}
+
 	if _, ok := validMethods[rq.RqMethod]; !ok {
 		return errors.New("rq method is not valid")
 	}
 
+	if !stringtool.Empty(rq.RqBodyMatch) {
+		if _, ok := validBodyMatches[rq.RqBodyMatch]; !ok {
+			return errors.New("rq body match is not valid")
+		}
+
+		if rq.RqBodyMatch != db.BodyMatchRaw && !stringtool.Empty(rq.RqBody) && !jsontool.Valid(rq.RqBody) {
+			return errors.New("rq body is not valid json")
+		}
+	}
+
 	if len(rq.RqQueryParams) > 0 {
 		for _, qp := range rq.RqQueryParams {
 			if stringtool.Empty(qp.Key) {
@@ -174,35 +529,306 @@ func (rq createMockRQ) Validate() error {
 		}
 	}
 
-	if stringtool.Empty(rq.RqPath) || !strings.HasPrefix(rq.RqPath, "/") {
-		return errors.New("rq path is empty")
+	if !stringtool.Empty(rq.RqQueryMatch) {
+		if _, ok := validQueryMatches[rq.RqQueryMatch]; !ok {
+			return errors.New("rq query match is not valid")
+		}
 	}
 
-	//RS
-	if rq.RsStatus <= 0 {
+	for _, qr := range rq.RqQueryRules {
+		if stringtool.Empty(qr.Key) {
+			return errors.New("query rule key is empty")
+		}
+
+		if _, ok := validQueryRules[qr.Match]; !ok {
+			return fmt.Errorf("query rule [%s] match is not valid", qr.Key)
+		}
+
+		switch qr.Match {
+		case db.QueryRulePresent, db.QueryRuleAbsent:
+			if qr.Value != "" {
+				return fmt.Errorf("query rule [%s] value is not allowed for [%s] match", qr.Key, qr.Match)
+			}
+		case db.QueryRuleRegex:
+			_, err := regexp.Compile(qr.Value)
+			if err != nil {
+				return fmt.Errorf("query rule [%s] regex is not valid: %s", qr.Key, err.Error())
+			}
+		default:
+			if stringtool.Empty(qr.Value) {
+				return fmt.Errorf("query rule [%s] value is empty", qr.Key)
+			}
+		}
+	}
+
+	err := rq.validatePath()
+	if err != nil {
+		return err
+	}
+
+	for _, h := range rq.RqHeaders {
+		if stringtool.Empty(h.Name) {
+			return errors.New("rq header name is empty")
+		}
+
+		if _, ok := validHeaderMatches[h.Match]; !ok {
+			return fmt.Errorf("rq header [%s] match is not valid", h.Name)
+		}
+
+		switch h.Match {
+		case db.HeaderMatchPresent, db.HeaderMatchAbsent:
+			if h.Value != "" {
+				return fmt.Errorf("rq header [%s] value is not allowed for [%s] match", h.Name, h.Match)
+			}
+		case db.HeaderMatchRegex:
+			_, err := regexp.Compile(h.Value)
+			if err != nil {
+				return fmt.Errorf("rq header [%s] regex is not valid: %s", h.Name, err.Error())
+			}
+		default:
+			if stringtool.Empty(h.Value) {
+				return fmt.Errorf("rq header [%s] value is empty", h.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// dbMock gets db mock with request matchers filled in
+func (rq requestPattern) dbMock() (db.Mock, error) {
+	queryParams, err := json.Marshal(maptool.UnsortJSONMap(rq.RqQueryParams))
+	if err != nil {
+		return db.Mock{}, err
+	}
+
+	var bodyPredicates []byte
+	if len(rq.RqBodyPredicates) > 0 {
+		bodyPredicates, err = json.Marshal(rq.RqBodyPredicates)
+		if err != nil {
+			return db.Mock{}, err
+		}
+	}
+
+	var queryRules []byte
+	if len(rq.RqQueryRules) > 0 {
+		queryRules, err = json.Marshal(rq.RqQueryRules)
+		if err != nil {
+			return db.Mock{}, err
+		}
+	}
+
+	var rqHeaders []byte
+	if len(rq.RqHeaders) > 0 {
+		rqHeaders, err = json.Marshal(rq.RqHeaders)
+		if err != nil {
+			return db.Mock{}, err
+		}
+	}
+
+	return db.Mock{
+		RqMethod:         rq.RqMethod,
+		RqPath:           rq.RqPath,
+		RqPathMatch:      rq.pathMatch(),
+		RqPathRegex:      rq.RqPathRegex,
+		RqBody:           rq.RqBody,
+		RqBodyMatch:      rq.RqBodyMatch,
+		RqBodyPredicates: bodyPredicates,
+		RqQueryParams:    queryParams,
+		RqQueryMatch:     rq.RqQueryMatch,
+		RqQueryRules:     queryRules,
+		RqHeaders:        rqHeaders,
+	}, nil
+}
+
+func (rq createMockRQ) validateProxy() error {
+	if rq.RsStatus != 0 || len(rq.RsHeaders) > 0 || !stringtool.Empty(rq.RsBody) || rq.RsTemplate {
+		return errors.New("rs status, headers, body and template are not allowed with proxy rs type")
+	}
+
+	if len(rq.RsSequence) > 0 || len(rq.RsVariants) > 0 {
+		return errors.New("rs sequence and variants are not allowed with proxy rs type")
+	}
+
+	return validateProxy(rq.RsProxyURL, rq.RsProxy)
+}
+
+func (rq createMockRQ) validateFault() error {
+	if stringtool.Empty(rq.RsFault) {
+		if rq.RsFaultBytes != 0 {
+			return errors.New("rs fault bytes are allowed only for truncated fault")
+		}
+
+		return nil
+	}
+
+	if _, ok := validFaults[rq.RsFault]; !ok {
+		return errors.New("rs fault is not valid")
+	}
+
+	if rq.RsFaultBytes < 0 || (rq.RsFault != db.FaultTruncated && rq.RsFaultBytes != 0) {
+		return errors.New("rs fault bytes not valid")
+	}
+
+	return nil
+}
+
+func (rq createMockRQ) validateSequence() error {
+	if len(rq.RsSequence) == 0 {
+		if !stringtool.Empty(rq.RsSequenceMode) {
+			return errors.New("rs sequence mode is allowed only with rs sequence")
+		}
+
+		return nil
+	}
+
+	if _, ok := validSequenceModes[rq.RsSequenceMode]; !ok && !stringtool.Empty(rq.RsSequenceMode) {
+		return errors.New("rs sequence mode is not valid")
+	}
+
+	if rq.RsStatus != 0 || len(rq.RsHeaders) > 0 || !stringtool.Empty(rq.RsBody) {
+		return errors.New("rs status, headers and body are not allowed with rs sequence")
+	}
+
+	for i, rs := range rq.RsSequence {
+		err := validateResponse(rs.Status, rs.Headers, rs.Body, rq.RsTemplate)
+		if err != nil {
+			return fmt.Errorf("rs sequence [%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (rq createMockRQ) validateVariants() error {
+	if len(rq.RsVariants) == 0 {
+		return nil
+	}
+
+	if len(rq.RsSequence) > 0 {
+		return errors.New("rs variants are not allowed with rs sequence")
+	}
+
+	if rq.RsStatus != 0 || len(rq.RsHeaders) > 0 || !stringtool.Empty(rq.RsBody) {
+		return errors.New("rs status, headers and body are not allowed with rs variants")
+	}
+
+	for i, v := range rq.RsVariants {
+		if v.Weight <= 0 {
+			return fmt.Errorf("rs variant [%d] weight not valid", i)
+		}
+
+		err := validateResponse(v.Status, v.Headers, v.Body, rq.RsTemplate)
+		if err != nil {
+			return fmt.Errorf("rs variant [%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func validateResponse(status int, headers []maptool.SortedJSONMap, body string, template bool) error {
+	if status <= 0 {
 		return errors.New("rs status not valid")
 	}
 
-	if len(rq.RsHeaders) > 0 {
-		for _, h := range rq.RsHeaders {
-			if stringtool.Empty(h.Key) {
-				return errors.New("header is empty")
+	for _, h := range headers {
+		if stringtool.Empty(h.Key) {
+			return errors.New("header is empty")
+		}
+
+		for _, v := range h.Values {
+			if stringtool.Empty(v) {
+				return errors.New("header value is empty")
 			}
 
-			for _, v := range h.Values {
-				if stringtool.Empty(v) {
-					return errors.New("header value is empty")
+			if template {
+				_, err := templatetool.Parse(v)
+				if err != nil {
+					return fmt.Errorf("header [%s] template is not valid: %s", h.Key, err.Error())
 				}
 			}
 		}
 	}
 
+	if template {
+		_, err := templatetool.Parse(body)
+		if err != nil {
+			return fmt.Errorf("rs body template is not valid: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (rq createMockRQ) validateDelay() error {
+	switch rq.RsDelayDistribution {
+	case "", db.DelayFixed:
+		if rq.RsDelayMs < 0 {
+			return errors.New("rs delay not valid")
+		}
+	case db.DelayUniform:
+		if rq.RsDelayMinMs < 0 || rq.RsDelayMaxMs < rq.RsDelayMinMs {
+			return errors.New("rs delay min/max not valid")
+		}
+	case db.DelayLognormal:
+		if rq.RsDelayMedianMs <= 0 || rq.RsDelaySigma < 0 {
+			return errors.New("rs delay median/sigma not valid")
+		}
+	default:
+		return errors.New("rs delay distribution is not valid")
+	}
+
+	return nil
+}
+
+// pathMatch gets path match type, infers it from path & regex if not set
+func (rq requestPattern) pathMatch() string {
+	mock := db.Mock{RqPath: rq.RqPath, RqPathMatch: rq.RqPathMatch, RqPathRegex: rq.RqPathRegex}
+
+	return mock.GetPathMatch()
+}
+
+func (rq requestPattern) validatePath() error {
+	if _, ok := validPathMatches[rq.pathMatch()]; !ok {
+		return errors.New("rq path match is not valid")
+	}
+
+	if rq.pathMatch() == db.PathMatchRegex {
+		if stringtool.Empty(rq.RqPathRegex) {
+			return errors.New("rq path regex is empty")
+		}
+
+		_, err := regexp.Compile(rq.RqPathRegex)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errPathRegexNotValid, err.Error())
+		}
+
+		return nil
+	}
+
+	if !stringtool.Empty(rq.RqPathRegex) {
+		return errors.New("rq path regex is allowed only for regex path match")
+	}
+
+	if stringtool.Empty(rq.RqPath) || !strings.HasPrefix(rq.RqPath, "/") {
+		return errors.New("rq path is empty")
+	}
+
+	if rq.pathMatch() == db.PathMatchTemplate {
+		_, err := pathtool.Parse(rq.RqPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 type createMockRS struct {
 	baseRS
-	ID int `json:"id"`
+	ID         int      `json:"id"`
+	Violations []string `json:"violations,omitempty"`
 }
 
 func createMockHandler(w http.ResponseWriter, r *http.Request) {
@@ -222,7 +848,11 @@ func createMockHandler(w http.ResponseWriter, r *http.Request) {
 	err = rq.Validate()
 	if err != nil {
 		logger.Errorf("request is not valid: [%s]", err.Error())
-		rs.setError(myerrors.ErrBadRequest)
+		if errors.Is(err, errPathRegexNotValid) {
+			rs.setError(myerrors.ErrPathRegexNotValid)
+		} else {
+			rs.setError(myerrors.ErrBadRequest)
+		}
 		writeResponse(w, rs, http.StatusBadRequest)
 		return
 	}
@@ -255,9 +885,39 @@ func createMockHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	queryParams, err := json.Marshal(maptool.UnsortJSONMap(rq.RqQueryParams))
+	pattern, err := rq.dbMock()
+	if err != nil {
+		logger.Errorf("failed to marshal rq with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	var sequence []byte
+	if len(rq.RsSequence) > 0 {
+		sequence, err = json.Marshal(newDBSequence(rq.RsSequence))
+		if err != nil {
+			logger.Errorf("failed to marshal rs sequence with error [%s]", err.Error())
+			rs.setError(myerrors.ErrInternal)
+			writeResponse(w, rs, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var variants []byte
+	if len(rq.RsVariants) > 0 {
+		variants, err = json.Marshal(newDBVariants(rq.RsVariants))
+		if err != nil {
+			logger.Errorf("failed to marshal rs variants with error [%s]", err.Error())
+			rs.setError(myerrors.ErrInternal)
+			writeResponse(w, rs, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	proxy, err := rq.RsProxy.marshal()
 	if err != nil {
-		logger.Errorf("failed to marshal query params with error [%s]", err.Error())
+		logger.Errorf("failed to marshal rs proxy with error [%s]", err.Error())
 		rs.setError(myerrors.ErrInternal)
 		writeResponse(w, rs, http.StatusInternalServerError)
 		return
@@ -272,18 +932,82 @@ func createMockHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	mock := db.Mock{
-		Name:          rq.Name,
-		Active:        true,
-		GroupID:       rq.GroupID,
-		RqMethod:      rq.RqMethod,
-		RqPath:        rq.RqPath,
-		RqBody:        rq.RqBody,
-		RqQueryParams: queryParams,
-		RsStatus:      rq.RsStatus,
-		RsHeaders:     headers,
-		RsBody:        rq.RsBody,
-	}
-	err = mock.Create()
+		Name:     rq.Name,
+		Active:   true,
+		GroupID:  rq.GroupID,
+		Priority: rq.Priority,
+
+		ScenarioName:          rq.ScenarioName,
+		ScenarioRequiredState: rq.ScenarioRequiredState,
+		ScenarioNewState:      rq.ScenarioNewState,
+
+		RqMethod:         pattern.RqMethod,
+		RqPath:           pattern.RqPath,
+		RqPathMatch:      pattern.RqPathMatch,
+		RqPathRegex:      pattern.RqPathRegex,
+		RqBody:           pattern.RqBody,
+		RqBodyMatch:      pattern.RqBodyMatch,
+		RqBodyPredicates: pattern.RqBodyPredicates,
+		RqQueryParams:    pattern.RqQueryParams,
+		RqQueryMatch:     pattern.RqQueryMatch,
+		RqQueryRules:     pattern.RqQueryRules,
+		RqHeaders:        pattern.RqHeaders,
+		RsType:           rq.RsType,
+		RsStatus:         rq.RsStatus,
+		RsHeaders:        headers,
+		RsBody:           rq.RsBody,
+		RsTemplate:       rq.RsTemplate,
+
+		RsProxyURL: rq.RsProxyURL,
+		RsProxy:    proxy,
+
+		RsSequence:     sequence,
+		RsSequenceMode: rq.RsSequenceMode,
+
+		RsVariants: variants,
+
+		RsDelayDistribution: rq.RsDelayDistribution,
+		RsDelayMs:           rq.RsDelayMs,
+		RsDelayMinMs:        rq.RsDelayMinMs,
+		RsDelayMaxMs:        rq.RsDelayMaxMs,
+		RsDelayMedianMs:     rq.RsDelayMedianMs,
+		RsDelaySigma:        rq.RsDelaySigma,
+
+		RsFault:      rq.RsFault,
+		RsFaultBytes: rq.RsFaultBytes,
+	}
+
+	rs.Violations, err = app.ValidateMockResponses(mock)
+	if err != nil {
+		logger.Errorf("failed to validate mock responses with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+	if len(rs.Violations) > 0 {
+		logger.Errorf("mock responses violate openapi spec of group [%d]: %s", rq.GroupID, strings.Join(rs.Violations, "; "))
+		rs.setError(myerrors.ErrMockResponseNotValid)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	err = app.CheckMock(mock)
+	if err != nil {
+		logger.Errorf("mock does not compile with error [%s]", err.Error())
+		rs.setError(myerrors.ErrMockNotValid)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	if stringtool.Empty(rq.ScenarioName) {
+		err = mock.Create()
+	} else {
+		var scenario db.Scenario
+		scenario, err = mock.CreateWithScenario()
+		if err == nil {
+			app.SetScenarioState(scenario.Name, scenario.State)
+		}
+	}
 	if err != nil {
 		logger.Errorf("failed to create mock with error [%s]", err.Error())
 		rs.setError(myerrors.ErrInternal)
@@ -291,6 +1015,11 @@ func createMockHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = app.UpsertMock(mock)
+	if err != nil {
+		logger.Errorf("failed to add mock [%d] to index with error [%s]", mock.ID, err.Error())
+	}
+
 	rs.ID = mock.ID
 	rs.setSuccess()
 	writeResponse(w, rs, http.StatusCreated)
@@ -326,6 +1055,16 @@ func activateMockHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	mockDB.Active = !mockDB.Active
+	if mockDB.Active {
+		err = app.CheckMock(mockDB)
+		if err != nil {
+			logger.Errorf("mock [%d] does not compile with error [%s]", mockDB.ID, err.Error())
+			rs.setError(myerrors.ErrMockNotValid)
+			writeResponse(w, rs, http.StatusBadRequest)
+			return
+		}
+	}
+
 	err = mockDB.Update()
 	if err != nil {
 		logger.Errorf("failed to activate mock with error [%s]", err.Error())
@@ -334,6 +1073,11 @@ func activateMockHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = app.UpsertMock(mockDB)
+	if err != nil {
+		logger.Errorf("failed to update mock [%d] in index with error [%s]", mockDB.ID, err.Error())
+	}
+
 	rs.setSuccess()
 	writeResponse(w, rs, http.StatusOK)
 }
@@ -375,6 +1119,45 @@ func deleteMockHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	app.RemoveMock(mockID)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func resetMockHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("reset mock handler...")
+
+	rs := baseRS{}
+
+	mockID, err := getID(r, mockIDKey)
+	if err != nil {
+		logger.Errorf("failed to get mock_id with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	mockDB := db.Mock{ID: mockID}
+	ok, err := mockDB.One()
+	if err != nil {
+		logger.Errorf("failed to check if mock exists with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		logger.Errorf("mock with id [%d] does not exist", mockID)
+		rs.setError(myerrors.ErrMockNotExists)
+		writeResponse(w, rs, http.StatusConflict)
+		return
+	}
+
+	if !app.ResetSequence(mockID) {
+		logger.Infof("mock [%d] is not active, nothing to reset", mockID)
+	}
+
 	rs.setSuccess()
 	writeResponse(w, rs, http.StatusOK)
 }
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/openapitool"
+	"github.com/mmiloslav/mock/pkg/pathtool"
+)
+
+// openAPIResponse is a response of operation picked for mock
+type openAPIResponse struct {
+	code     string
+	status   int
+	response *openapitool.Response
+}
+
+// newOpenAPIResponses gets operation responses with their status, 2xx first,
+// default response is used only when operation has no other
+func newOpenAPIResponses(op *openapitool.Operation) []openAPIResponse {
+	var result []openAPIResponse
+	for code, rs := range op.Responses {
+		if rs == nil || code == "default" {
+			continue
+		}
+
+		status, err := strconv.Atoi(strings.NewReplacer("X", "0", "x", "0").Replace(code))
+		if err != nil || status < 100 || status > 599 {
+			continue
+		}
+
+		result = append(result, openAPIResponse{code: code, status: status, response: rs})
+	}
+
+	if rs, ok := op.Responses["default"]; ok && rs != nil && len(result) == 0 {
+		result = append(result, openAPIResponse{code: "default", status: http.StatusOK, response: rs})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		iOK, jOK := result[i].status/100 == 2, result[j].status/100 == 2
+		if iOK != jOK {
+			return iOK
+		}
+
+		return result[i].status < result[j].status
+	})
+
+	return result
+}
+
+// newOpenAPIBody gets response body text, non json string examples are kept as is
+func newOpenAPIBody(mediaType string, sample interface{}) (string, error) {
+	if s, ok := sample.(string); ok && !openapitool.JSONMediaType(mediaType) {
+		return s, nil
+	}
+
+	if sample == nil {
+		return "", nil
+	}
+
+	b, err := json.Marshal(sample)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// newOpenAPIMocks converts operation to mocks of group, one per response & named example,
+// only the first 2xx one is active
+func newOpenAPIMocks(mo openapitool.MethodOperation, basePath string, groupID int) ([]db.Mock, error) {
+	path := basePath + mo.Path
+	pathMatch := db.PathMatchExact
+	if pathtool.IsTemplate(path) {
+		_, err := pathtool.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+
+		pathMatch = db.PathMatchTemplate
+	}
+
+	baseName := mo.Operation.OperationID
+	if baseName == "" {
+		baseName = mo.Method + " " + path
+	}
+
+	var mocks []db.Mock
+	for _, rs := range newOpenAPIResponses(mo.Operation) {
+		headers := map[string][]string{}
+		for name, h := range rs.response.Headers {
+			value := h.Example
+			if value == nil && h.Schema != nil {
+				value = h.Schema.Sample()
+			}
+
+			if value != nil {
+				headers[http.CanonicalHeaderKey(name)] = []string{fmt.Sprint(value)}
+			}
+		}
+
+		examples := map[string]interface{}{"": nil}
+		mediaType, ok := openapitool.PickMediaType(rs.response.Content)
+		if ok {
+			headers["Content-Type"] = []string{mediaType}
+
+			mt := rs.response.Content[mediaType]
+			examples[""], _ = mt.Sample()
+			if mt.Example == nil && len(mt.Examples) > 1 {
+				examples = map[string]interface{}{}
+				for _, name := range mt.ExampleNames() {
+					examples[name] = mt.Examples[name].Value
+				}
+			}
+		}
+
+		rsHeaders, err := json.Marshal(headers)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(examples))
+		for name := range examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, exampleName := range names {
+			body, err := newOpenAPIBody(mediaType, examples[exampleName])
+			if err != nil {
+				return nil, err
+			}
+
+			name := baseName + " " + rs.code
+			if exampleName != "" {
+				name += " " + exampleName
+			}
+
+			mocks = append(mocks, db.Mock{
+				Name:         name,
+				Active:       len(mocks) == 0,
+				GroupID:      groupID,
+				RqMethod:     mo.Method,
+				RqPath:       path,
+				RqPathMatch:  pathMatch,
+				RqQueryMatch: db.QueryMatchSubset,
+				RsStatus:     rs.status,
+				RsHeaders:    rsHeaders,
+				RsBody:       body,
+			})
+		}
+	}
+
+	return mocks, nil
+}
+
+func importOpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("import openapi handler...")
+
+	rs := importGroupRS{IDs: []int{}}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Errorf("failed to read request body with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	doc, err := openapitool.Load(data)
+	if err != nil {
+		logger.Errorf("failed to parse openapi document with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	// all operations are converted before any mock is created, so a bad one leaves group untouched
+	var mocks []db.Mock
+	names := map[string]struct{}{}
+	for _, mo := range doc.Operations() {
+		opMocks, err := newOpenAPIMocks(mo, doc.BasePath(), groupID)
+		if err != nil {
+			logger.Errorf("failed to convert operation [%s %s] with error [%s]", mo.Method, mo.Path, err.Error())
+			rs.setError(myerrors.ErrBadRequest)
+			writeResponse(w, rs, http.StatusBadRequest)
+			return
+		}
+
+		for _, mock := range opMocks {
+			if _, ok := names[mock.Name]; ok {
+				rs.Skipped++
+				continue
+			}
+			names[mock.Name] = struct{}{}
+
+			ok, err := db.MockExists(mock.Name, groupID)
+			if err != nil {
+				logger.Errorf("failed to check if mock exists with error [%s]", err.Error())
+				rs.setError(myerrors.ErrInternal)
+				writeResponse(w, rs, http.StatusInternalServerError)
+				return
+			}
+			if ok {
+				rs.Skipped++
+				continue
+			}
+
+			mocks = append(mocks, mock)
+		}
+	}
+
+	err = db.CreateMocks(mocks)
+	if err != nil {
+		logger.Errorf("failed to create mocks with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	for _, mock := range mocks {
+		err = app.UpsertMock(mock)
+		if err != nil {
+			logger.Errorf("failed to add mock [%d] to index with error [%s]", mock.ID, err.Error())
+		}
+
+		rs.IDs = append(rs.IDs, mock.ID)
+	}
+
+	logger.Infof("imported [%d] mocks from openapi into group [%d], skipped [%d] existing", len(rs.IDs), groupID, rs.Skipped)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusCreated)
+}
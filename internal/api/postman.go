@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/jsontool"
+	"github.com/mmiloslav/mock/pkg/pathtool"
+	"github.com/mmiloslav/mock/pkg/postmantool"
+	"github.com/sirupsen/logrus"
+)
+
+// newPostmanMock converts saved example of collection request to mock of group,
+// example original request wins over the saved one
+func newPostmanMock(item postmantool.Item, example postmantool.Response, groupID int, vars map[string]string, logger *logrus.Entry) (db.Mock, bool, error) {
+	rq := example.OriginalRequest
+	if rq == nil {
+		rq = item.Request
+	}
+
+	method := strings.ToUpper(rq.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	if _, ok := validMethods[method]; !ok || example.Code <= 0 {
+		return db.Mock{}, false, nil
+	}
+
+	path := rq.URL.PathTemplate(vars)
+	pathMatch := db.PathMatchExact
+	if pathtool.IsTemplate(path) {
+		_, err := pathtool.Parse(path)
+		if err != nil {
+			logger.Errorf("skipping example [%s] of request [%s], path is not valid: [%s]", example.Name, item.Name, err.Error())
+			return db.Mock{}, false, nil
+		}
+
+		pathMatch = db.PathMatchTemplate
+	}
+
+	body := ""
+	if method != http.MethodGet {
+		body = rq.Body.Text(vars)
+	}
+
+	rsHeaders := map[string][]string{}
+	for _, h := range example.Header {
+		name := http.CanonicalHeaderKey(h.Key)
+		if _, ok := skippedImportHeaders[name]; ok || h.Disabled || name == "" {
+			continue
+		}
+
+		rsHeaders[name] = append(rsHeaders[name], h.Value)
+	}
+
+	exampleName := example.Name
+	if exampleName == "" {
+		exampleName = strconv.Itoa(example.Code)
+	}
+
+	m := db.Mock{
+		Name:        item.Name + " - " + exampleName,
+		Active:      true,
+		GroupID:     groupID,
+		RqMethod:    method,
+		RqPath:      path,
+		RqPathMatch: pathMatch,
+		RqBody:      body,
+		RsStatus:    example.Code,
+		RsBody:      example.Body,
+	}
+
+	if body != "" && jsontool.Valid(body) {
+		m.RqBodyMatch = db.BodyMatchJSON
+	}
+
+	var err error
+	if query := rq.URL.QueryParams(vars); len(query) > 0 {
+		m.RqQueryParams, err = json.Marshal(query)
+		if err != nil {
+			return db.Mock{}, false, err
+		}
+	}
+
+	m.RsHeaders, err = json.Marshal(rsHeaders)
+	if err != nil {
+		return db.Mock{}, false, err
+	}
+
+	return m, true, nil
+}
+
+type importedGroup struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	IDs     []int  `json:"ids"`
+	Skipped int    `json:"skipped"`
+}
+
+type importPostmanRS struct {
+	baseRS
+	Groups []importedGroup `json:"groups"`
+}
+
+func importPostmanHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("import postman handler...")
+
+	rs := importPostmanRS{Groups: []importedGroup{}}
+
+	collection, err := postmantool.Decode(r.Body)
+	if err != nil {
+		logger.Errorf("failed to decode postman collection with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	// all examples are converted before anything is written, groups & mocks are created in one transaction
+	vars := collection.Variables()
+	var groups []db.Group
+	var skipped []int
+	byName := map[string]int{}
+	for _, folder := range collection.Folders() {
+		i, ok := byName[folder.Name]
+		if !ok {
+			group, exists, err := db.GetGroupByName(folder.Name)
+			if err != nil {
+				logger.Errorf("failed to get group [%s] with error [%s]", folder.Name, err.Error())
+				rs.setError(myerrors.ErrInternal)
+				writeResponse(w, rs, http.StatusInternalServerError)
+				return
+			}
+			if !exists {
+				group = db.Group{Name: folder.Name}
+			}
+
+			i = len(groups)
+			byName[folder.Name] = i
+			groups = append(groups, group)
+			skipped = append(skipped, 0)
+		}
+
+		group := &groups[i]
+		for _, item := range folder.Requests {
+			if len(item.Response) == 0 {
+				skipped[i]++
+				continue
+			}
+
+			for _, example := range item.Response {
+				mock, ok, err := newPostmanMock(item, example, group.ID, vars, logger)
+				if err != nil {
+					logger.Errorf("failed to convert example [%s] of request [%s] with error [%s]", example.Name, item.Name, err.Error())
+					rs.setError(myerrors.ErrBadRequest)
+					writeResponse(w, rs, http.StatusBadRequest)
+					return
+				}
+				if !ok || containsMockName(group.Mocks, mock.Name) {
+					skipped[i]++
+					continue
+				}
+
+				if group.ID != 0 {
+					ok, err = db.MockExists(mock.Name, group.ID)
+					if err != nil {
+						logger.Errorf("failed to check if mock exists with error [%s]", err.Error())
+						rs.setError(myerrors.ErrInternal)
+						writeResponse(w, rs, http.StatusInternalServerError)
+						return
+					}
+					if ok {
+						skipped[i]++
+						continue
+					}
+				}
+
+				group.Mocks = append(group.Mocks, mock)
+			}
+		}
+	}
+
+	err = db.CreateGroupsWithMocks(groups)
+	if err != nil {
+		logger.Errorf("failed to create groups & mocks with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	for i, group := range groups {
+		imported := importedGroup{ID: group.ID, Name: group.Name, IDs: []int{}, Skipped: skipped[i]}
+		for _, mock := range group.Mocks {
+			err = app.UpsertMock(mock)
+			if err != nil {
+				logger.Errorf("failed to add mock [%d] to index with error [%s]", mock.ID, err.Error())
+			}
+
+			imported.IDs = append(imported.IDs, mock.ID)
+		}
+
+		logger.Infof("imported [%d] mocks from postman into group [%d], skipped [%d]", len(imported.IDs), group.ID, imported.Skipped)
+
+		rs.Groups = append(rs.Groups, imported)
+	}
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusCreated)
+}
+
+// containsMockName checks if mock with name is among mocks
+func containsMockName(mocks []db.Mock, name string) bool {
+	for _, m := range mocks {
+		if m.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
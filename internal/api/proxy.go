@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/pkg/maptool"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+)
+
+var validRsTypes = map[string]struct{}{
+	db.RsTypeStatic: {},
+	db.RsTypeProxy:  {},
+}
+
+type ProxyOptions struct {
+	HeadersAdd    []maptool.SortedJSONMap `json:"headers_add,omitempty"`
+	HeadersRemove []string                `json:"headers_remove,omitempty"`
+	PathPattern   string                  `json:"path_pattern,omitempty"`
+	PathReplace   string                  `json:"path_replace,omitempty"`
+}
+
+func newProxyOptions(opts db.ProxyOptions) *ProxyOptions {
+	if len(opts.HeadersAdd) == 0 && len(opts.HeadersRemove) == 0 && stringtool.Empty(opts.PathPattern) {
+		return nil
+	}
+
+	return &ProxyOptions{
+		HeadersAdd:    maptool.SortJSONMap(opts.HeadersAdd),
+		HeadersRemove: opts.HeadersRemove,
+		PathPattern:   opts.PathPattern,
+		PathReplace:   opts.PathReplace,
+	}
+}
+
+// marshal gets proxy options as db json, nil if not set
+func (opts *ProxyOptions) marshal() ([]byte, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(db.ProxyOptions{
+		HeadersAdd:    maptool.UnsortJSONMap(opts.HeadersAdd),
+		HeadersRemove: opts.HeadersRemove,
+		PathPattern:   opts.PathPattern,
+		PathReplace:   opts.PathReplace,
+	})
+}
+
+func validateProxy(proxyURL string, opts *ProxyOptions) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("proxy url must be absolute http or https url")
+	}
+
+	if opts == nil {
+		return nil
+	}
+
+	for _, h := range opts.HeadersAdd {
+		if stringtool.Empty(h.Key) {
+			return errors.New("proxy header is empty")
+		}
+	}
+
+	for _, h := range opts.HeadersRemove {
+		if stringtool.Empty(h) {
+			return errors.New("proxy removed header is empty")
+		}
+	}
+
+	if stringtool.Empty(opts.PathPattern) {
+		if !stringtool.Empty(opts.PathReplace) {
+			return errors.New("proxy path replace is allowed only with path pattern")
+		}
+
+		return nil
+	}
+
+	_, err = regexp.Compile(opts.PathPattern)
+	if err != nil {
+		return fmt.Errorf("proxy path pattern is not valid: %s", err.Error())
+	}
+
+	return nil
+}
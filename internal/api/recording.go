@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+)
+
+type startRecordingRQ struct {
+	GroupID   int      `json:"group_id"`
+	RqHeaders []string `json:"rq_headers"`
+	RsHeaders []string `json:"rs_headers"`
+}
+
+func (rq startRecordingRQ) Validate() error {
+	if rq.GroupID <= 0 {
+		return errors.New("groupID not valid")
+	}
+
+	for _, h := range rq.RqHeaders {
+		if stringtool.Empty(h) {
+			return errors.New("rq header is empty")
+		}
+	}
+
+	for _, h := range rq.RsHeaders {
+		if stringtool.Empty(h) {
+			return errors.New("rs header is empty")
+		}
+	}
+
+	return nil
+}
+
+type recordingRS struct {
+	baseRS
+	Recording app.Recording `json:"recording"`
+}
+
+func getRecordingHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("get recording handler...")
+
+	rs := recordingRS{Recording: app.GetRecording()}
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func startRecordingHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("start recording handler...")
+
+	rs := recordingRS{}
+
+	rq := startRecordingRQ{}
+	err := json.NewDecoder(r.Body).Decode(&rq)
+	if err != nil {
+		logger.Errorf("failed to decode request with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	err = rq.Validate()
+	if err != nil {
+		logger.Errorf("request is not valid: [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	ok, err := db.GroupExistsByID(rq.GroupID)
+	if err != nil {
+		logger.Errorf("failed to check if group exists with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		logger.Errorf("group with id [%d] does not exist", rq.GroupID)
+		rs.setError(myerrors.ErrGroupNotExists)
+		writeResponse(w, rs, http.StatusConflict)
+		return
+	}
+
+	err = app.StartRecording(rq.GroupID, rq.RqHeaders, rq.RsHeaders)
+	if err != nil {
+		logger.Errorf("failed to start recording with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.Recording = app.GetRecording()
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func stopRecordingHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("stop recording handler...")
+
+	rs := recordingRS{}
+
+	recording, err := app.StopRecording()
+	if err != nil {
+		logger.Errorf("failed to activate recorded mocks with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.Recording = recording
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
@@ -35,12 +35,55 @@ var routes = []route{
 	{Name: "Get Mocks", Method: http.MethodGet, Pattern: "/api/v1/mocks", HandlerFunc: getMocksHandler, MiddlewareAuthFunc: requestIDMiddleware},
 	{Name: "Create Mock", Method: http.MethodPost, Pattern: "/api/v1/mocks", HandlerFunc: createMockHandler, MiddlewareAuthFunc: requestIDMiddleware},
 	{Name: "Activate Mock", Method: http.MethodPatch, Pattern: "/api/v1/mocks/{mock_id}/activate", HandlerFunc: activateMockHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Reset Mock Sequence", Method: http.MethodPatch, Pattern: "/api/v1/mocks/{mock_id}/reset", HandlerFunc: resetMockHandler, MiddlewareAuthFunc: requestIDMiddleware},
 	{Name: "Delete Mock", Method: http.MethodDelete, Pattern: "/api/v1/mocks/{mock_id}", HandlerFunc: deleteMockHandler, MiddlewareAuthFunc: requestIDMiddleware},
 
 	// GROUP
 	{Name: "Get Groups", Method: http.MethodGet, Pattern: "/api/v1/groups", HandlerFunc: getGroupsHandler, MiddlewareAuthFunc: requestIDMiddleware},
 	{Name: "Create Group", Method: http.MethodPost, Pattern: "/api/v1/groups", HandlerFunc: createGroupHandler, MiddlewareAuthFunc: requestIDMiddleware},
 	{Name: "Delete Group", Method: http.MethodDelete, Pattern: "/api/v1/groups/{group_id}", HandlerFunc: deleteGroupHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Export Group", Method: http.MethodGet, Pattern: "/api/v1/groups/{group_id}/export", HandlerFunc: exportGroupHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Import Group", Method: http.MethodPost, Pattern: "/api/v1/groups/{group_id}/import", HandlerFunc: importGroupHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Import Group OpenAPI", Method: http.MethodPost, Pattern: "/api/v1/groups/{group_id}/import/openapi", HandlerFunc: importOpenAPIHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Import Postman Collection", Method: http.MethodPost, Pattern: "/api/v1/groups/import/postman", HandlerFunc: importPostmanHandler, MiddlewareAuthFunc: requestIDMiddleware},
+
+	// FALLBACK
+	{Name: "Get Fallback", Method: http.MethodGet, Pattern: "/api/v1/fallback", HandlerFunc: getFallbackHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Set Fallback", Method: http.MethodPut, Pattern: "/api/v1/fallback", HandlerFunc: setFallbackHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Delete Fallback", Method: http.MethodDelete, Pattern: "/api/v1/fallback", HandlerFunc: deleteFallbackHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Get Group Fallback", Method: http.MethodGet, Pattern: "/api/v1/groups/{group_id}/fallback", HandlerFunc: getFallbackHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Set Group Fallback", Method: http.MethodPut, Pattern: "/api/v1/groups/{group_id}/fallback", HandlerFunc: setFallbackHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Delete Group Fallback", Method: http.MethodDelete, Pattern: "/api/v1/groups/{group_id}/fallback", HandlerFunc: deleteFallbackHandler, MiddlewareAuthFunc: requestIDMiddleware},
+
+	// SCENARIO
+	{Name: "Get Scenarios", Method: http.MethodGet, Pattern: "/api/v1/scenarios", HandlerFunc: getScenariosHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Create Scenario", Method: http.MethodPost, Pattern: "/api/v1/scenarios", HandlerFunc: createScenarioHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Reset Scenarios", Method: http.MethodPatch, Pattern: "/api/v1/scenarios/reset", HandlerFunc: resetScenariosHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Set Scenario State", Method: http.MethodPut, Pattern: "/api/v1/scenarios/{scenario_id}/state", HandlerFunc: setScenarioStateHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Reset Scenario", Method: http.MethodPatch, Pattern: "/api/v1/scenarios/{scenario_id}/reset", HandlerFunc: resetScenarioHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Delete Scenario", Method: http.MethodDelete, Pattern: "/api/v1/scenarios/{scenario_id}", HandlerFunc: deleteScenarioHandler, MiddlewareAuthFunc: requestIDMiddleware},
+
+	// CHAOS
+	{Name: "Get Group Chaos", Method: http.MethodGet, Pattern: "/api/v1/groups/{group_id}/chaos", HandlerFunc: getChaosHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Set Group Chaos", Method: http.MethodPut, Pattern: "/api/v1/groups/{group_id}/chaos", HandlerFunc: setChaosHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Delete Group Chaos", Method: http.MethodDelete, Pattern: "/api/v1/groups/{group_id}/chaos", HandlerFunc: deleteChaosHandler, MiddlewareAuthFunc: requestIDMiddleware},
+
+	// RECORDING
+	{Name: "Get Recording", Method: http.MethodGet, Pattern: "/api/v1/recording", HandlerFunc: getRecordingHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Start Recording", Method: http.MethodPatch, Pattern: "/api/v1/recording/start", HandlerFunc: startRecordingHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Stop Recording", Method: http.MethodPatch, Pattern: "/api/v1/recording/stop", HandlerFunc: stopRecordingHandler, MiddlewareAuthFunc: requestIDMiddleware},
+
+	// REQUEST JOURNAL
+	{Name: "Get Requests", Method: http.MethodGet, Pattern: "/api/v1/requests", HandlerFunc: getRequestsHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Delete Requests", Method: http.MethodDelete, Pattern: "/api/v1/requests", HandlerFunc: deleteRequestsHandler, MiddlewareAuthFunc: requestIDMiddleware},
+
+	// VERIFY
+	{Name: "Verify Requests", Method: http.MethodPost, Pattern: "/api/v1/verify", HandlerFunc: verifyHandler, MiddlewareAuthFunc: requestIDMiddleware},
+
+	// OPENAPI SPEC
+	{Name: "Get Group Spec", Method: http.MethodGet, Pattern: "/api/v1/groups/{group_id}/openapi", HandlerFunc: getSpecHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Set Group Spec", Method: http.MethodPut, Pattern: "/api/v1/groups/{group_id}/openapi", HandlerFunc: setSpecHandler, MiddlewareAuthFunc: requestIDMiddleware},
+	{Name: "Delete Group Spec", Method: http.MethodDelete, Pattern: "/api/v1/groups/{group_id}/openapi", HandlerFunc: deleteSpecHandler, MiddlewareAuthFunc: requestIDMiddleware},
 }
 
 // newRouter creates mux.Router
@@ -0,0 +1,295 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+)
+
+const scenarioIDKey = "scenario_id"
+
+type Scenario struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+func newScenarios(dbScenarios []db.Scenario) []Scenario {
+	scenarios := make([]Scenario, 0, len(dbScenarios))
+	for _, s := range dbScenarios {
+		scenarios = append(scenarios, newScenario(s))
+	}
+
+	return scenarios
+}
+
+func newScenario(dbScenario db.Scenario) Scenario {
+	return Scenario{
+		ID:    dbScenario.ID,
+		Name:  dbScenario.Name,
+		State: dbScenario.State,
+	}
+}
+
+type getScenariosRS struct {
+	baseRS
+	Scenarios []Scenario `json:"scenarios"`
+}
+
+func getScenariosHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("get scenarios handler...")
+
+	rs := getScenariosRS{}
+
+	dbScenarios, err := db.GetScenarios()
+	if err != nil {
+		logger.Errorf("failed to get scenarios with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.Scenarios = newScenarios(dbScenarios)
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+type createScenarioRQ struct {
+	Name string `json:"name"`
+}
+
+func (rq createScenarioRQ) Validate() error {
+	if stringtool.Empty(rq.Name) {
+		return errors.New("name is empty")
+	}
+
+	return nil
+}
+
+type createScenarioRS struct {
+	baseRS
+	ID int `json:"id"`
+}
+
+func createScenarioHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("create scenario handler...")
+
+	rs := createScenarioRS{}
+	rq := createScenarioRQ{}
+	err := json.NewDecoder(r.Body).Decode(&rq)
+	if err != nil {
+		logger.Errorf("failed to decode request with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	err = rq.Validate()
+	if err != nil {
+		logger.Errorf("request is not valid: [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	scenario := db.Scenario{Name: rq.Name}
+	ok, err := scenario.One()
+	if err != nil {
+		logger.Errorf("failed to check if scenario already exists with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+	if ok {
+		logger.Errorf("scenario with name [%s] already exists", rq.Name)
+		rs.setError(myerrors.ErrScenarioAlreadyExists)
+		writeResponse(w, rs, http.StatusConflict)
+		return
+	}
+
+	scenario = db.Scenario{Name: rq.Name, State: db.ScenarioStarted}
+	err = scenario.Create()
+	if err != nil {
+		logger.Errorf("failed to create scenario with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	app.SetScenarioState(scenario.Name, scenario.State)
+
+	rs.ID = scenario.ID
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusCreated)
+}
+
+type setScenarioStateRQ struct {
+	State string `json:"state"`
+}
+
+func (rq setScenarioStateRQ) Validate() error {
+	if stringtool.Empty(rq.State) {
+		return errors.New("state is empty")
+	}
+
+	return nil
+}
+
+func setScenarioStateHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("set scenario state handler...")
+
+	rs := baseRS{}
+
+	rq := setScenarioStateRQ{}
+	err := json.NewDecoder(r.Body).Decode(&rq)
+	if err != nil {
+		logger.Errorf("failed to decode request with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	err = rq.Validate()
+	if err != nil {
+		logger.Errorf("request is not valid: [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	scenario, ok := getExistingScenario(w, r)
+	if !ok {
+		return
+	}
+
+	scenario.State = rq.State
+	err = scenario.Update()
+	if err != nil {
+		logger.Errorf("failed to update scenario with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	app.SetScenarioState(scenario.Name, scenario.State)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func resetScenarioHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("reset scenario handler...")
+
+	rs := baseRS{}
+
+	scenario, ok := getExistingScenario(w, r)
+	if !ok {
+		return
+	}
+
+	scenario.State = db.ScenarioStarted
+	err := scenario.Update()
+	if err != nil {
+		logger.Errorf("failed to reset scenario with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	app.SetScenarioState(scenario.Name, scenario.State)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func resetScenariosHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("reset scenarios handler...")
+
+	rs := baseRS{}
+
+	err := db.ResetScenarios()
+	if err != nil {
+		logger.Errorf("failed to reset scenarios with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	err = app.LoadScenarios()
+	if err != nil {
+		logger.Errorf("failed to reload scenarios with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func deleteScenarioHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("delete scenario handler...")
+
+	rs := baseRS{}
+
+	scenario, ok := getExistingScenario(w, r)
+	if !ok {
+		return
+	}
+
+	err := scenario.Delete()
+	if err != nil {
+		logger.Errorf("failed to delete scenario with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	app.RemoveScenario(scenario.Name)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+// getExistingScenario gets scenario by id from path, writes error response if it does not exist
+func getExistingScenario(w http.ResponseWriter, r *http.Request) (db.Scenario, bool) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	rs := baseRS{}
+
+	scenarioID, err := getID(r, scenarioIDKey)
+	if err != nil {
+		logger.Errorf("failed to get scenario id with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return db.Scenario{}, false
+	}
+
+	scenario := db.Scenario{ID: scenarioID}
+	ok, err := scenario.One()
+	if err != nil {
+		logger.Errorf("failed to check if scenario exists with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return db.Scenario{}, false
+	}
+	if !ok {
+		logger.Errorf("scenario with id [%d] does not exist", scenarioID)
+		rs.setError(myerrors.ErrScenarioNotFound)
+		writeResponse(w, rs, http.StatusNotFound)
+		return db.Scenario{}, false
+	}
+
+	return scenario, true
+}
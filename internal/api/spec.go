@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/openapitool"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+)
+
+type GroupSpec struct {
+	GroupID  int    `json:"group_id"`
+	Document string `json:"document"`
+	Enabled  bool   `json:"enabled"`
+	Status   int    `json:"status"`
+}
+
+func newGroupSpec(dbSpec db.GroupSpec) GroupSpec {
+	return GroupSpec{
+		GroupID:  dbSpec.GroupID,
+		Document: dbSpec.Document,
+		Enabled:  dbSpec.Enabled,
+		Status:   dbSpec.GetStatus(),
+	}
+}
+
+type setSpecRQ struct {
+	Document string `json:"document"`
+	Enabled  bool   `json:"enabled"`
+	Status   int    `json:"status"`
+}
+
+func (rq setSpecRQ) Validate() error {
+	if stringtool.Empty(rq.Document) {
+		return errors.New("document is empty")
+	}
+
+	if rq.Status != 0 && (rq.Status < 400 || rq.Status > 599) {
+		return errors.New("status must be in [400, 599]")
+	}
+
+	_, err := openapitool.Load([]byte(rq.Document))
+
+	return err
+}
+
+type getSpecRS struct {
+	baseRS
+	Spec GroupSpec `json:"spec"`
+}
+
+func getSpecHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("get spec handler...")
+
+	rs := getSpecRS{}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	spec := db.GroupSpec{GroupID: groupID}
+	ok, err := spec.One()
+	if err != nil {
+		logger.Errorf("failed to get openapi spec with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		logger.Errorf("openapi spec of group [%d] does not exist", groupID)
+		rs.setError(myerrors.ErrSpecNotFound)
+		writeResponse(w, rs, http.StatusNotFound)
+		return
+	}
+
+	rs.Spec = newGroupSpec(spec)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func setSpecHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("set spec handler...")
+
+	rs := baseRS{}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	rq := setSpecRQ{}
+	err := json.NewDecoder(r.Body).Decode(&rq)
+	if err != nil {
+		logger.Errorf("failed to decode request with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	err = rq.Validate()
+	if err != nil {
+		logger.Errorf("request is not valid: [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	spec := db.GroupSpec{
+		GroupID:  groupID,
+		Document: rq.Document,
+		Enabled:  rq.Enabled,
+		Status:   rq.Status,
+	}
+	err = spec.Save()
+	if err != nil {
+		logger.Errorf("failed to save openapi spec with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	err = app.SetSpec(spec)
+	if err != nil {
+		logger.Errorf("failed to apply openapi spec with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
+
+func deleteSpecHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("delete spec handler...")
+
+	rs := baseRS{}
+
+	groupID, ok := getExistingGroupID(w, r)
+	if !ok {
+		return
+	}
+
+	err := db.DeleteGroupSpec(groupID)
+	if err != nil {
+		logger.Errorf("failed to delete openapi spec with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	app.RemoveSpec(groupID)
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mmiloslav/mock/internal/app"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+)
+
+// verifyRQ is a request pattern with expected exact count or min/max range of matching requests
+type verifyRQ struct {
+	requestPattern
+
+	Count *int `json:"count"`
+	Min   *int `json:"min"`
+	Max   *int `json:"max"`
+}
+
+func (rq verifyRQ) Validate() error {
+	err := rq.requestPattern.Validate()
+	if err != nil {
+		return err
+	}
+
+	if rq.Count != nil {
+		if rq.Min != nil || rq.Max != nil {
+			return errors.New("count is not allowed with min/max")
+		}
+
+		if *rq.Count < 0 {
+			return errors.New("count not valid")
+		}
+
+		return nil
+	}
+
+	if rq.Min == nil && rq.Max == nil {
+		return errors.New("count or min/max is required")
+	}
+
+	if (rq.Min != nil && *rq.Min < 0) || (rq.Max != nil && *rq.Max < 0) {
+		return errors.New("min/max not valid")
+	}
+
+	if rq.Min != nil && rq.Max != nil && *rq.Min > *rq.Max {
+		return errors.New("min is greater than max")
+	}
+
+	return nil
+}
+
+// passed checks if actual count of matching requests is expected
+func (rq verifyRQ) passed(count int) bool {
+	if rq.Count != nil {
+		return count == *rq.Count
+	}
+
+	if rq.Min != nil && count < *rq.Min {
+		return false
+	}
+
+	if rq.Max != nil && count > *rq.Max {
+		return false
+	}
+
+	return true
+}
+
+type verifyRS struct {
+	baseRS
+	Passed   bool               `json:"passed"`
+	Count    int                `json:"count"`
+	Requests []app.JournalEntry `json:"requests"`
+}
+
+func verifyHandler(w http.ResponseWriter, r *http.Request) {
+	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
+	logger.Info("verify handler...")
+
+	rs := verifyRS{}
+	rq := verifyRQ{}
+	err := json.NewDecoder(r.Body).Decode(&rq)
+	if err != nil {
+		logger.Errorf("failed to decode request with error [%s]", err.Error())
+		rs.setError(myerrors.ErrBadRequest)
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	err = rq.Validate()
+	if err != nil {
+		logger.Errorf("request is not valid: [%s]", err.Error())
+		if errors.Is(err, errPathRegexNotValid) {
+			rs.setError(myerrors.ErrPathRegexNotValid)
+		} else {
+			rs.setError(myerrors.ErrBadRequest)
+		}
+		writeResponse(w, rs, http.StatusBadRequest)
+		return
+	}
+
+	pattern, err := rq.dbMock()
+	if err != nil {
+		logger.Errorf("failed to marshal rq with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.Requests, err = app.MatchJournal(pattern)
+	if err != nil {
+		logger.Errorf("failed to match requests with error [%s]", err.Error())
+		rs.setError(myerrors.ErrInternal)
+		writeResponse(w, rs, http.StatusInternalServerError)
+		return
+	}
+
+	rs.Count = len(rs.Requests)
+	rs.Passed = rq.passed(rs.Count)
+	if !rs.Passed {
+		logger.Infof("verification of [%s %s] failed with [%d] matching requests", rq.RqMethod, rq.RqPath, rs.Count)
+	}
+
+	rs.setSuccess()
+	writeResponse(w, rs, http.StatusOK)
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/mylog"
+)
+
+// chaosState is a group chaos policy with its own rng, so seeded runs are reproducible
+type chaosState struct {
+	mu    sync.Mutex
+	rules []db.ChaosRule
+	rng   *rand.Rand
+}
+
+// chaosOutcome is a result of chaos rules evaluation for request
+type chaosOutcome struct {
+	delay  time.Duration
+	status int
+	body   string
+	fault  string
+}
+
+// applied checks if any chaos rule fired
+func (o chaosOutcome) applied() bool {
+	return o.delay > 0 || o.status > 0 || o.fault != ""
+}
+
+// chaos keeps chaos policies by group id
+var chaos = struct {
+	mu      sync.RWMutex
+	byGroup map[int]*chaosState
+}{byGroup: map[int]*chaosState{}}
+
+// LoadChaosPolicies loads all chaos policies from db
+func LoadChaosPolicies() error {
+	policies, err := db.GetChaosPolicies()
+	if err != nil {
+		return err
+	}
+
+	byGroup := make(map[int]*chaosState, len(policies))
+	for _, p := range policies {
+		state, err := newChaosState(p)
+		if err != nil {
+			mylog.Logger.Errorf("failed to load chaos policy of group [%d] with error [%s], skipping", p.GroupID, err.Error())
+			continue
+		}
+
+		byGroup[p.GroupID] = state
+	}
+
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+
+	chaos.byGroup = byGroup
+
+	return nil
+}
+
+// SetChaosPolicy adds or replaces group chaos policy, rng is reseeded
+func SetChaosPolicy(p db.ChaosPolicy) error {
+	state, err := newChaosState(p)
+	if err != nil {
+		return err
+	}
+
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+
+	chaos.byGroup[p.GroupID] = state
+
+	return nil
+}
+
+// RemoveChaosPolicy removes group chaos policy
+func RemoveChaosPolicy(groupID int) {
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+
+	delete(chaos.byGroup, groupID)
+}
+
+func newChaosState(p db.ChaosPolicy) (*chaosState, error) {
+	rules, err := p.GetRules()
+	if err != nil {
+		return nil, err
+	}
+
+	seed := uint64(p.Seed)
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	return &chaosState{rules: rules, rng: rand.New(rand.NewPCG(seed, seed))}, nil
+}
+
+// evalChaos makes one roll for request against cumulative probabilities of group policy rules,
+// so every rule fires with its own probability and at most one rule fires. Rules are checked in policy
+// order, total probability is at most 1 and no rule fires for the rest
+func evalChaos(groupID int) chaosOutcome {
+	chaos.mu.RLock()
+	state, ok := chaos.byGroup[groupID]
+	chaos.mu.RUnlock()
+
+	if !ok {
+		return chaosOutcome{}
+	}
+
+	state.mu.Lock()
+	roll := state.rng.Float64()
+	state.mu.Unlock()
+
+	for _, rule := range state.rules {
+		if roll >= rule.Probability {
+			roll -= rule.Probability
+			continue
+		}
+
+		switch rule.Action {
+		case db.ChaosDelay:
+			return chaosOutcome{delay: time.Duration(rule.DelayMs) * time.Millisecond}
+		case db.ChaosStatus:
+			return chaosOutcome{status: rule.Status, body: rule.Body}
+		case db.ChaosFault:
+			return chaosOutcome{fault: rule.Fault}
+		}
+
+		return chaosOutcome{}
+	}
+
+	return chaosOutcome{}
+}
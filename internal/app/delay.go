@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/mmiloslav/mock/internal/db"
+)
+
+// getDelay gets mock response delay according to its distribution
+func getDelay(m db.Mock) time.Duration {
+	var ms float64
+	switch m.GetDelayDistribution() {
+	case db.DelayUniform:
+		ms = float64(m.RsDelayMinMs) + rand.Float64()*float64(m.RsDelayMaxMs-m.RsDelayMinMs)
+	case db.DelayLognormal:
+		ms = float64(m.RsDelayMedianMs) * math.Exp(rand.NormFloat64()*m.RsDelaySigma)
+	default:
+		ms = float64(m.RsDelayMs)
+	}
+
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// sleep waits for delay, returns context error if request is cancelled before
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
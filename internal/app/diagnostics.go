@@ -0,0 +1,50 @@
+package app
+
+import (
+	"sort"
+)
+
+// nearMissLimit is how many closest mocks are reported when nothing matches
+const nearMissLimit = 3
+
+// nearMiss is a mock which did not match request with reasons why
+type nearMiss struct {
+	MockID  int      `json:"mock_id"`
+	GroupID int      `json:"group_id"`
+	Name    string   `json:"name"`
+	Diffs   []string `json:"diffs"`
+}
+
+// nearMisses gets closest mocks to request, the fewer diffs the closer
+func (idx *mockIndex) nearMisses(rq *matchRQ, limit int) []nearMiss {
+	idx.mu.RLock()
+	mocks := make([]*compiledMock, 0, len(idx.mocks))
+	for _, cm := range idx.mocks {
+		mocks = append(mocks, cm)
+	}
+	idx.mu.RUnlock()
+
+	misses := make([]nearMiss, 0, len(mocks))
+	for _, cm := range mocks {
+		diffs := cm.explain(rq)
+		if len(diffs) == 0 {
+			continue
+		}
+
+		misses = append(misses, nearMiss{MockID: cm.mock.ID, GroupID: cm.mock.GroupID, Name: cm.mock.Name, Diffs: diffs})
+	}
+
+	sort.Slice(misses, func(i, j int) bool {
+		if len(misses[i].Diffs) != len(misses[j].Diffs) {
+			return len(misses[i].Diffs) < len(misses[j].Diffs)
+		}
+
+		return misses[i].MockID < misses[j].MockID
+	})
+
+	if len(misses) > limit {
+		misses = misses[:limit]
+	}
+
+	return misses
+}
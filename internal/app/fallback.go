@@ -0,0 +1,131 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/sirupsen/logrus"
+)
+
+// fallbacks keeps configured fallbacks by group id, global one is under db.GlobalFallbackGroupID
+var fallbacks = struct {
+	mu      sync.RWMutex
+	byGroup map[int]db.Fallback
+}{byGroup: map[int]db.Fallback{}}
+
+// LoadFallbacks loads all fallbacks from db
+func LoadFallbacks() error {
+	dbFallbacks, err := db.GetFallbacks()
+	if err != nil {
+		return err
+	}
+
+	byGroup := make(map[int]db.Fallback, len(dbFallbacks))
+	for _, f := range dbFallbacks {
+		byGroup[f.GroupID] = f
+	}
+
+	fallbacks.mu.Lock()
+	defer fallbacks.mu.Unlock()
+
+	fallbacks.byGroup = byGroup
+
+	return nil
+}
+
+// SetFallback adds or replaces group fallback
+func SetFallback(f db.Fallback) {
+	fallbacks.mu.Lock()
+	defer fallbacks.mu.Unlock()
+
+	fallbacks.byGroup[f.GroupID] = f
+}
+
+// RemoveFallback removes group fallback
+func RemoveFallback(groupID int) {
+	fallbacks.mu.Lock()
+	defer fallbacks.mu.Unlock()
+
+	delete(fallbacks.byGroup, groupID)
+}
+
+// groupHeader names group whose fallback serves unmatched request, closest near miss does not pick group
+const groupHeader = "X-Mock-Group"
+
+// getFallback gets fallback of the group set in request group header, global one otherwise
+func getFallback(r *http.Request) (db.Fallback, bool) {
+	fallbacks.mu.RLock()
+	defer fallbacks.mu.RUnlock()
+
+	if groupID, err := strconv.Atoi(r.Header.Get(groupHeader)); err == nil && groupID != db.GlobalFallbackGroupID {
+		if f, ok := fallbacks.byGroup[groupID]; ok {
+			return f, true
+		}
+	}
+
+	f, ok := fallbacks.byGroup[db.GlobalFallbackGroupID]
+
+	return f, ok
+}
+
+// writeFallback writes response for request which no mock matches
+func writeFallback(w http.ResponseWriter, r *http.Request, body string, misses []nearMiss, logger *logrus.Entry) {
+	rs := notFoundRS{NearMisses: misses}
+	rs.setError(myerrors.ErrNotFound)
+
+	f, ok := getFallback(r)
+	if !ok {
+		writeResponse(w, rs, http.StatusNotFound)
+		return
+	}
+
+	logger.Infof("using [%s] fallback of group [%d]", f.Mode, f.GroupID)
+
+	switch f.Mode {
+	case db.FallbackCustom:
+		headers, err := f.GetHeaders()
+		if err != nil {
+			logger.Errorf("failed to get fallback headers with error [%s]", err.Error())
+			rs.setError(myerrors.ErrInternal)
+			writeResponse(w, rs.baseRS, http.StatusInternalServerError)
+			return
+		}
+
+		for k, vals := range headers {
+			for _, v := range vals {
+				w.Header().Add(k, v)
+			}
+		}
+
+		w.WriteHeader(f.Status)
+		_, err = w.Write([]byte(f.Body))
+		if err != nil {
+			logger.Errorf("failed to write fallback body with error [%s]", err.Error())
+		}
+	case db.FallbackProxy:
+		opts, err := f.GetProxy()
+		if err != nil {
+			logger.Errorf("failed to get fallback proxy options with error [%s]", err.Error())
+			rs.setError(myerrors.ErrInternal)
+			writeResponse(w, rs.baseRS, http.StatusInternalServerError)
+			return
+		}
+
+		target, err := newProxyTarget(f.ProxyURL, opts)
+		if err != nil {
+			logger.Errorf("failed to parse fallback proxy target [%s] with error [%s]", f.ProxyURL, err.Error())
+			rs.setError(myerrors.ErrInternal)
+			writeResponse(w, rs.baseRS, http.StatusInternalServerError)
+			return
+		}
+
+		proxyRequest(w, r, target, body, logger)
+	case db.FallbackDiagnostics:
+		writeResponse(w, rs, http.StatusNotImplemented)
+	default:
+		writeResponse(w, rs, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/mmiloslav/mock/internal/db"
+)
+
+// garbageSize is how many random bytes are written for garbage fault
+const garbageSize = 1024
+
+// writeFault hijacks connection and breaks response according to fault type,
+// proxy mocks have no own status, 502 is written for them.
+// Headers already set on w like X-Request-ID are written before mock headers
+func writeFault(w http.ResponseWriter, fault string, status int, headers map[string][]string, body string, truncateBytes int) error {
+	if status == 0 {
+		status = http.StatusBadGateway
+	}
+
+	rawHeaders := w.Header().Clone()
+	for k, vals := range headers {
+		for _, v := range vals {
+			rawHeaders.Add(k, v)
+		}
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return errors.New("response writer does not support hijacking")
+	}
+
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch fault {
+	case db.FaultConnectionReset:
+		if tcp, ok := conn.(*net.TCPConn); ok {
+			return tcp.SetLinger(0)
+		}
+
+		return nil
+	case db.FaultEmptyReply:
+		return nil
+	case db.FaultMalformedChunked:
+		writeStatusLine(buf, status, rawHeaders, "Transfer-Encoding", "chunked")
+		buf.WriteString("zz\r\n" + body + "\r\n")
+	case db.FaultGarbage:
+		garbage := make([]byte, garbageSize)
+		_, err := rand.Read(garbage)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(garbage)
+	case db.FaultTruncated:
+		writeStatusLine(buf, status, rawHeaders, "Content-Length", strconv.Itoa(len(body)))
+		if truncateBytes < len(body) {
+			body = body[:truncateBytes]
+		}
+
+		buf.WriteString(body)
+	default:
+		return fmt.Errorf("unknown fault [%s]", fault)
+	}
+
+	return buf.Flush()
+}
+
+// writeStatusLine writes raw http/1.1 status line & headers
+func writeStatusLine(buf *bufio.ReadWriter, status int, headers http.Header, key, value string) {
+	fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	for k, vals := range headers {
+		for _, v := range vals {
+			fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+		}
+	}
+
+	fmt.Fprintf(buf, "%s: %s\r\n\r\n", key, value)
+}
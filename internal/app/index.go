@@ -0,0 +1,157 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/pathtool"
+)
+
+// mockIndex keeps compiled active mocks grouped by method & first path segment,
+// db stays the source of truth and index is patched by api on every change
+type mockIndex struct {
+	mu       sync.RWMutex
+	mocks    map[int]*compiledMock
+	byMethod map[string]*methodBucket
+}
+
+// methodBucket keeps mocks of one method, mocks without literal first segment go to any
+type methodBucket struct {
+	byPrefix map[string][]*compiledMock
+	any      []*compiledMock
+}
+
+var index = &mockIndex{
+	mocks:    map[int]*compiledMock{},
+	byMethod: map[string]*methodBucket{},
+}
+
+// LoadMocks loads all active mocks from db into index
+func LoadMocks() error {
+	dbMocks, err := db.GetActiveMocks()
+	if err != nil {
+		return err
+	}
+
+	mocks := make(map[int]*compiledMock, len(dbMocks))
+	for _, m := range dbMocks {
+		cm, err := compileMock(m)
+		if err != nil {
+			mylog.Logger.Errorf("failed to compile mock [%d] with error [%s], skipping", m.ID, err.Error())
+			continue
+		}
+
+		mocks[m.ID] = cm
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.mocks = mocks
+	index.rebuild()
+
+	return nil
+}
+
+// CheckMock checks if mock compiles into index, mock is checked before save so saved one is always served
+func CheckMock(m db.Mock) error {
+	_, err := compileMock(m)
+
+	return err
+}
+
+// UpsertMock adds or replaces mock in index, inactive mock is removed
+func UpsertMock(m db.Mock) error {
+	if !m.Active {
+		RemoveMock(m.ID)
+		return nil
+	}
+
+	cm, err := compileMock(m)
+	if err != nil {
+		return err
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.mocks[m.ID] = cm
+	index.rebuild()
+
+	return nil
+}
+
+// RemoveMock removes mock from index
+func RemoveMock(id int) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	delete(index.mocks, id)
+	index.rebuild()
+}
+
+// RemoveGroup removes all mocks of group from index, other mocks keep their state
+func RemoveGroup(groupID int) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	for id, cm := range index.mocks {
+		if cm.mock.GroupID == groupID {
+			delete(index.mocks, id)
+		}
+	}
+	index.rebuild()
+}
+
+// rebuild regroups mocks by method & prefix, must be called under write lock
+func (idx *mockIndex) rebuild() {
+	byMethod := map[string]*methodBucket{}
+	for _, cm := range idx.mocks {
+		bucket, ok := byMethod[cm.mock.RqMethod]
+		if !ok {
+			bucket = &methodBucket{byPrefix: map[string][]*compiledMock{}}
+			byMethod[cm.mock.RqMethod] = bucket
+		}
+
+		prefix, ok := cm.prefix()
+		if !ok {
+			bucket.any = append(bucket.any, cm)
+			continue
+		}
+
+		bucket.byPrefix[prefix] = append(bucket.byPrefix[prefix], cm)
+	}
+
+	idx.byMethod = byMethod
+}
+
+// candidates gets mocks which may match request method & path
+func (idx *mockIndex) candidates(method, path string) []*compiledMock {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	bucket, ok := idx.byMethod[method]
+	if !ok {
+		return nil
+	}
+
+	byPrefix := bucket.byPrefix[pathtool.FirstSegment(path)]
+	mocks := make([]*compiledMock, 0, len(byPrefix)+len(bucket.any))
+	mocks = append(mocks, byPrefix...)
+	mocks = append(mocks, bucket.any...)
+
+	return mocks
+}
+
+// prefix gets first literal path segment of mock
+func (cm *compiledMock) prefix() (string, bool) {
+	switch cm.mock.GetPathMatch() {
+	case db.PathMatchExact:
+		return pathtool.FirstSegment(cm.mock.RqPath), true
+	case db.PathMatchTemplate:
+		return cm.template.Prefix()
+	default:
+		return "", false
+	}
+}
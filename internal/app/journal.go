@@ -0,0 +1,247 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/env"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+)
+
+const (
+	journalKey         = "journal_entry"
+	journalSizeVarName = "JOURNAL_SIZE"
+	defaultJournalSize = 1000
+)
+
+// JournalEntry is a request received on mock port with its outcome
+type JournalEntry struct {
+	ID        int64               `json:"id"`
+	RequestID string              `json:"request_id"`
+	Time      time.Time           `json:"time"`
+	Method    string              `json:"method"`
+	URL       string              `json:"url"`
+	Path      string              `json:"path"`
+	Headers   map[string][]string `json:"headers,omitempty"`
+	Body      string              `json:"body,omitempty"`
+	MockID    int                 `json:"mock_id,omitempty"`
+	GroupID   int                 `json:"group_id,omitempty"`
+	Variant   *int                `json:"variant,omitempty"`
+	Status    int                 `json:"status"`
+	Fault     string              `json:"fault,omitempty"`
+	LatencyMs int64               `json:"latency_ms"`
+}
+
+// JournalFilter selects journal entries, zero fields match anything
+type JournalFilter struct {
+	MockID    int
+	GroupID   int
+	Unmatched bool
+	Path      string
+	Status    int
+	From      time.Time
+	To        time.Time
+}
+
+// match checks if entry passes filter, path is checked by pathRe compiled from filter path wildcard
+func (f JournalFilter) match(e JournalEntry, pathRe *regexp.Regexp) bool {
+	if f.MockID > 0 && e.MockID != f.MockID {
+		return false
+	}
+
+	if f.GroupID > 0 && e.GroupID != f.GroupID {
+		return false
+	}
+
+	if f.Unmatched && e.MockID != 0 {
+		return false
+	}
+
+	if pathRe != nil && !pathRe.MatchString(e.Path) {
+		return false
+	}
+
+	if f.Status > 0 && e.Status != f.Status {
+		return false
+	}
+
+	if !f.From.IsZero() && e.Time.Before(f.From) {
+		return false
+	}
+
+	if !f.To.IsZero() && e.Time.After(f.To) {
+		return false
+	}
+
+	return true
+}
+
+// journal is a bounded ring buffer of latest requests, the oldest entry is overwritten when full
+var journal = struct {
+	mu      sync.RWMutex
+	entries []JournalEntry
+	next    int
+	full    bool
+	lastID  int64
+}{entries: make([]JournalEntry, defaultJournalSize)}
+
+// InitJournal sizes request journal from env, default size is used if not set
+func InitJournal() error {
+	size := defaultJournalSize
+
+	val, err := env.GetVar(journalSizeVarName)
+	if err == nil {
+		size, err = strconv.Atoi(val)
+		if err != nil {
+			return err
+		}
+
+		if size <= 0 {
+			return errors.New("journal size must be positive")
+		}
+	}
+
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+
+	journal.entries = make([]JournalEntry, size)
+	journal.next = 0
+	journal.full = false
+
+	mylog.Logger.Infof("request journal keeps [%d] latest requests", size)
+
+	return nil
+}
+
+// addJournalEntry appends entry to journal and assigns its id
+func addJournalEntry(e JournalEntry) {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+
+	journal.lastID++
+	e.ID = journal.lastID
+
+	journal.entries[journal.next] = e
+	journal.next = (journal.next + 1) % len(journal.entries)
+	if journal.next == 0 {
+		journal.full = true
+	}
+}
+
+// GetJournal gets journal entries passing filter, oldest first
+func GetJournal(f JournalFilter) []JournalEntry {
+	var pathRe *regexp.Regexp
+	if !stringtool.Empty(f.Path) {
+		pathRe = stringtool.CompileWildcard(f.Path)
+	}
+
+	journal.mu.RLock()
+	defer journal.mu.RUnlock()
+
+	start, count := 0, journal.next
+	if journal.full {
+		start, count = journal.next, len(journal.entries)
+	}
+
+	result := []JournalEntry{}
+	for i := 0; i < count; i++ {
+		e := journal.entries[(start+i)%len(journal.entries)]
+		if f.match(e, pathRe) {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+// ClearJournal removes all journal entries
+func ClearJournal() {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+
+	journal.entries = make([]JournalEntry, len(journal.entries))
+	journal.next = 0
+	journal.full = false
+}
+
+// getJournalEntry gets journal entry of request being served
+func getJournalEntry(r *http.Request) *JournalEntry {
+	e, ok := r.Context().Value(journalKey).(*JournalEntry)
+	if !ok {
+		return &JournalEntry{}
+	}
+
+	return e
+}
+
+// journalMiddleware writes every request with its response status & latency to journal
+func journalMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		e := &JournalEntry{
+			Time:    start,
+			Method:  r.Method,
+			URL:     r.URL.String(),
+			Path:    r.URL.Path,
+			Headers: r.Header.Clone(),
+		}
+		e.RequestID, _ = r.Context().Value(requestIDKey).(string)
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), journalKey, e)))
+
+		e.Status = sw.status
+		e.LatencyMs = time.Since(start).Milliseconds()
+		addJournalEntry(*e)
+	})
+}
+
+// statusWriter remembers response status, hijacking & flushing are passed through
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := sw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+
+	return hj.Hijack()
+}
+
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,530 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/pkg/jsontool"
+	"github.com/mmiloslav/mock/pkg/pathtool"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+)
+
+// matchRQ is a request data used for mock matching
+type matchRQ struct {
+	method  string
+	path    string
+	body    string
+	query   url.Values
+	headers http.Header
+
+	parsed  bool
+	bodyDoc interface{}
+	bodyErr error
+}
+
+// json parses request body once
+func (rq *matchRQ) json() (interface{}, error) {
+	if !rq.parsed {
+		rq.bodyDoc, rq.bodyErr = jsontool.Parse(rq.body)
+		rq.parsed = true
+	}
+
+	return rq.bodyDoc, rq.bodyErr
+}
+
+// compiledMock is a mock with parsed matchers ready for matching
+type compiledMock struct {
+	mock        db.Mock
+	template    pathtool.Template
+	pathRe      *regexp.Regexp
+	body        interface{}
+	predicates  []jsontool.Predicate
+	queryParams map[string][]string
+	queryRules  []compiledQueryRule
+	headers     []compiledHeader
+	specificity int
+	matchers    int
+
+	proxy       *proxyTarget
+	responses   []compiledResponse
+	totalWeight int
+	seqMu       sync.Mutex
+	seqNext     int
+}
+
+type compiledQueryRule struct {
+	db.QueryRule
+	re *regexp.Regexp
+}
+
+type compiledHeader struct {
+	db.HeaderMatcher
+	re *regexp.Regexp
+}
+
+// compileMock parses all mock matchers
+func compileMock(m db.Mock) (*compiledMock, error) {
+	cm := &compiledMock{mock: m}
+
+	var err error
+	switch m.GetPathMatch() {
+	case db.PathMatchTemplate:
+		cm.template, err = pathtool.Parse(m.RqPath)
+		if err != nil {
+			return nil, err
+		}
+
+		cm.specificity = 2
+	case db.PathMatchRegex:
+		cm.pathRe, err = regexp.Compile(m.RqPathRegex)
+		if err != nil {
+			return nil, err
+		}
+
+		cm.specificity = 1
+	default:
+		cm.specificity = 3
+	}
+
+	if !stringtool.Empty(m.RqBody) {
+		if m.GetBodyMatch() != db.BodyMatchRaw {
+			cm.body, err = jsontool.Parse(m.RqBody)
+			if err != nil {
+				return nil, fmt.Errorf("rq body is not valid json: %s", err.Error())
+			}
+		}
+
+		cm.matchers++
+	}
+
+	predicates, err := m.GetRqBodyPredicates()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range predicates {
+		p, err := jsontool.ParsePredicate(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		cm.predicates = append(cm.predicates, p)
+	}
+
+	cm.queryParams, err = m.GetRqQueryParams()
+	if err != nil {
+		return nil, err
+	}
+
+	queryRules, err := m.GetRqQueryRules()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range queryRules {
+		cr := compiledQueryRule{QueryRule: rule}
+		switch rule.Match {
+		case db.QueryRuleRegex:
+			cr.re, err = regexp.Compile(rule.Value)
+			if err != nil {
+				return nil, err
+			}
+		case db.QueryRuleWildcard:
+			cr.re = stringtool.CompileWildcard(rule.Value)
+		}
+
+		cm.queryRules = append(cm.queryRules, cr)
+	}
+
+	headers, err := m.GetRqHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hm := range headers {
+		ch := compiledHeader{HeaderMatcher: hm}
+		if hm.Match == db.HeaderMatchRegex {
+			pattern := hm.Value
+			if hm.IgnoreCase {
+				pattern = "(?i)" + pattern
+			}
+
+			ch.re, err = regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		cm.headers = append(cm.headers, ch)
+	}
+
+	cm.matchers += len(cm.predicates) + len(cm.queryParams) + len(cm.queryRules) + len(cm.headers)
+	if m.ScenarioName != "" && m.ScenarioRequiredState != "" {
+		cm.matchers++
+	}
+
+	if m.GetRsType() == db.RsTypeProxy {
+		opts, err := m.GetRsProxy()
+		if err != nil {
+			return nil, err
+		}
+
+		cm.proxy, err = newProxyTarget(m.RsProxyURL, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cm.responses, err = compileResponses(m)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rs := range cm.responses {
+		cm.totalWeight += rs.weight
+	}
+
+	return cm, nil
+}
+
+// better checks if mock should be picked over other one:
+// priority first, then path specificity, then number of matchers, then lower id
+func (cm *compiledMock) better(o *compiledMock) bool {
+	if cm.mock.Priority != o.mock.Priority {
+		return cm.mock.Priority > o.mock.Priority
+	}
+
+	if cm.specificity != o.specificity {
+		return cm.specificity > o.specificity
+	}
+
+	if cm.matchers != o.matchers {
+		return cm.matchers > o.matchers
+	}
+
+	return cm.mock.ID < o.mock.ID
+}
+
+// matched is a mock which matches request with captured path params
+type matched struct {
+	cm     *compiledMock
+	params map[string]string
+}
+
+// matchMock picks best mock which matches request and returns captured path params,
+// mocks with exhausted fall through sequence are skipped. Response is not claimed, see nextResponse,
+// if claimResponse fails request is matched again using withoutMock
+func matchMock(mocks []*compiledMock, rq *matchRQ) (*compiledMock, map[string]string) {
+	var found []matched
+	for _, cm := range mocks {
+		params, ok := cm.match(rq)
+		if ok {
+			found = append(found, matched{cm: cm, params: params})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].cm.better(found[j].cm)
+	})
+
+	for _, m := range found {
+		if !m.cm.exhausted() {
+			return m.cm, m.params
+		}
+	}
+
+	return nil, nil
+}
+
+// withoutMock gets mocks except skipped one
+func withoutMock(mocks []*compiledMock, skipped *compiledMock) []*compiledMock {
+	result := make([]*compiledMock, 0, len(mocks))
+	for _, cm := range mocks {
+		if cm != skipped {
+			result = append(result, cm)
+		}
+	}
+
+	return result
+}
+
+// match checks if mock matches request and returns captured path params
+func (cm *compiledMock) match(rq *matchRQ) (map[string]string, bool) {
+	params, diff := cm.matchPath(rq.path)
+	if diff != "" {
+		return nil, false
+	}
+
+	if cm.matchBody(rq) != "" || cm.matchBodyPredicates(rq) != "" || len(cm.matchQuery(rq.query)) > 0 || len(cm.matchHeaders(rq.headers)) > 0 {
+		return nil, false
+	}
+
+	if cm.matchScenario() != "" {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// explain gets all reasons why mock does not match request
+func (cm *compiledMock) explain(rq *matchRQ) []string {
+	var diffs []string
+	if cm.mock.RqMethod != rq.method {
+		diffs = append(diffs, fmt.Sprintf("method differs: expected [%s], got [%s]", cm.mock.RqMethod, rq.method))
+	}
+
+	if _, diff := cm.matchPath(rq.path); diff != "" {
+		diffs = append(diffs, diff)
+	}
+
+	if diff := cm.matchBody(rq); diff != "" {
+		diffs = append(diffs, diff)
+	}
+
+	if diff := cm.matchBodyPredicates(rq); diff != "" {
+		diffs = append(diffs, diff)
+	}
+
+	diffs = append(diffs, cm.matchQuery(rq.query)...)
+	diffs = append(diffs, cm.matchHeaders(rq.headers)...)
+
+	if diff := cm.matchScenario(); diff != "" {
+		diffs = append(diffs, diff)
+	}
+
+	if cm.exhausted() {
+		diffs = append(diffs, "response sequence exhausted")
+	}
+
+	return diffs
+}
+
+// matchPath matches request path according to mock path match type, returns diff if not matched
+func (cm *compiledMock) matchPath(path string) (map[string]string, string) {
+	switch {
+	case cm.pathRe != nil:
+		match := cm.pathRe.FindStringSubmatch(path)
+		if match == nil {
+			return nil, fmt.Sprintf("path differs: [%s] does not match regex [%s]", path, cm.mock.RqPathRegex)
+		}
+
+		params := map[string]string{}
+		for i, name := range cm.pathRe.SubexpNames() {
+			if name != "" {
+				params[name] = match[i]
+			}
+		}
+
+		return params, ""
+	case cm.mock.GetPathMatch() == db.PathMatchTemplate:
+		params, ok := cm.template.Match(path)
+		if !ok {
+			return nil, fmt.Sprintf("path differs: [%s] does not match template [%s]", path, cm.mock.RqPath)
+		}
+
+		return params, ""
+	default:
+		if cm.mock.RqPath != path {
+			return nil, fmt.Sprintf("path differs: expected [%s], got [%s]", cm.mock.RqPath, path)
+		}
+
+		return map[string]string{}, ""
+	}
+}
+
+// matchBody matches request body according to mock body match type, empty mock body matches any
+func (cm *compiledMock) matchBody(rq *matchRQ) string {
+	if stringtool.Empty(cm.mock.RqBody) {
+		return ""
+	}
+
+	switch cm.mock.GetBodyMatch() {
+	case db.BodyMatchJSON, db.BodyMatchJSONContains:
+		actual, err := rq.json()
+		if err != nil {
+			return "body differs: request body is not valid json"
+		}
+
+		path, ok := jsontool.Diff(actual, cm.body, cm.mock.GetBodyMatch() == db.BodyMatchJSONContains)
+		if ok {
+			return fmt.Sprintf("body differs at [%s]", path)
+		}
+
+		return ""
+	default:
+		if cm.mock.RqBody != rq.body {
+			return "body differs"
+		}
+
+		return ""
+	}
+}
+
+// matchBodyPredicates evaluates all mock body predicates against request json body
+func (cm *compiledMock) matchBodyPredicates(rq *matchRQ) string {
+	if len(cm.predicates) == 0 {
+		return ""
+	}
+
+	doc, err := rq.json()
+	if err != nil {
+		return "body predicates failed: request body is not valid json"
+	}
+
+	for _, p := range cm.predicates {
+		if !p.Eval(doc) {
+			return fmt.Sprintf("body predicate [%s] failed", p.String())
+		}
+	}
+
+	return ""
+}
+
+// matchQuery matches request query params according to mock query match type & rules
+func (cm *compiledMock) matchQuery(query url.Values) []string {
+	var diffs []string
+	exact := cm.mock.GetQueryMatch() == db.QueryMatchExact
+	for k, expected := range cm.queryParams {
+		actual, ok := query[k]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("query param [%s] missing", k))
+			continue
+		}
+
+		if !containsAll(actual, expected) || (exact && !containsAll(expected, actual)) {
+			diffs = append(diffs, fmt.Sprintf("query param [%s] differs: expected %v, got %v", k, expected, actual))
+		}
+	}
+
+	allowed := map[string]struct{}{}
+	for _, rule := range cm.queryRules {
+		values, ok := query[rule.Key]
+		switch rule.Match {
+		case db.QueryRuleAbsent:
+			if ok {
+				diffs = append(diffs, fmt.Sprintf("query param [%s] must be absent", rule.Key))
+			}
+
+			continue
+		case db.QueryRulePresent:
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("query param [%s] missing", rule.Key))
+			}
+		default:
+			if !rule.match(values) {
+				diffs = append(diffs, fmt.Sprintf("query param [%s] does not match %s [%s]", rule.Key, rule.Match, rule.Value))
+			}
+		}
+
+		allowed[rule.Key] = struct{}{}
+	}
+
+	if exact {
+		for k := range query {
+			_, inParams := cm.queryParams[k]
+			_, inRules := allowed[k]
+			if !inParams && !inRules {
+				diffs = append(diffs, fmt.Sprintf("unexpected query param [%s]", k))
+			}
+		}
+	}
+
+	sort.Strings(diffs)
+
+	return diffs
+}
+
+// match checks regex & wildcard rules, passes if any of param values matches
+func (rule compiledQueryRule) match(values []string) bool {
+	for _, v := range values {
+		if rule.re != nil && rule.re.MatchString(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsAll checks if all expected values are in actual
+func containsAll(actual, expected []string) bool {
+	for _, e := range expected {
+		if !slices.Contains(actual, e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchHeaders checks all mock header rules against request headers
+func (cm *compiledMock) matchHeaders(headers http.Header) []string {
+	var diffs []string
+	for _, h := range cm.headers {
+		values := headers.Values(h.Name)
+		if h.match(values) {
+			continue
+		}
+
+		switch h.Match {
+		case db.HeaderMatchPresent:
+			diffs = append(diffs, fmt.Sprintf("header [%s] missing", h.Name))
+		case db.HeaderMatchAbsent:
+			diffs = append(diffs, fmt.Sprintf("header [%s] must be absent", h.Name))
+		default:
+			diffs = append(diffs, fmt.Sprintf("header [%s] does not match %s [%s], got %v", h.Name, h.Match, h.Value, values))
+		}
+	}
+
+	return diffs
+}
+
+// match checks header rule, passes if any of header values matches
+func (h compiledHeader) match(values []string) bool {
+	switch h.Match {
+	case db.HeaderMatchPresent:
+		return len(values) > 0
+	case db.HeaderMatchAbsent:
+		return len(values) == 0
+	}
+
+	for _, v := range values {
+		switch h.Match {
+		case db.HeaderMatchContains:
+			if h.IgnoreCase {
+				if strings.Contains(strings.ToLower(v), strings.ToLower(h.Value)) {
+					return true
+				}
+			} else if strings.Contains(v, h.Value) {
+				return true
+			}
+		case db.HeaderMatchRegex:
+			if h.re.MatchString(v) {
+				return true
+			}
+		default:
+			if h.IgnoreCase {
+				if strings.EqualFold(v, h.Value) {
+					return true
+				}
+			} else if v == h.Value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getPathParams gets path params captured by matched mock template
+func getPathParams(r *http.Request) map[string]string {
+	params, _ := r.Context().Value(pathParamsKey).(map[string]string)
+
+	return params
+}
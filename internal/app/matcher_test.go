@@ -0,0 +1,389 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"gorm.io/datatypes"
+)
+
+func mustCompile(t *testing.T, m db.Mock) *compiledMock {
+	t.Helper()
+
+	if m.RqMethod == "" {
+		m.RqMethod = http.MethodGet
+	}
+
+	if m.RsStatus == 0 {
+		m.RsStatus = http.StatusOK
+	}
+
+	cm, err := compileMock(m)
+	if err != nil {
+		t.Fatalf("compileMock() error = %v", err)
+	}
+
+	return cm
+}
+
+func newMatchRQ(method, target, body string, headers http.Header) *matchRQ {
+	u, _ := url.Parse(target)
+
+	return &matchRQ{method: method, path: u.Path, body: body, query: u.Query(), headers: headers}
+}
+
+func TestCompiledMockBetter(t *testing.T) {
+	tests := []struct {
+		name string
+		a    db.Mock
+		b    db.Mock
+		want bool
+	}{
+		{
+			name: "higher priority wins over specificity",
+			a:    db.Mock{ID: 2, Priority: 1, RqPathRegex: "^/users/.*$"},
+			b:    db.Mock{ID: 1, RqPath: "/users/1"},
+			want: true,
+		},
+		{
+			name: "exact path wins over template",
+			a:    db.Mock{ID: 2, RqPath: "/users/1"},
+			b:    db.Mock{ID: 1, RqPath: "/users/{id}"},
+			want: true,
+		},
+		{
+			name: "template wins over regex",
+			a:    db.Mock{ID: 2, RqPath: "/users/{id}"},
+			b:    db.Mock{ID: 1, RqPathRegex: "^/users/.*$"},
+			want: true,
+		},
+		{
+			name: "more matchers win on same specificity",
+			a:    db.Mock{ID: 2, RqPath: "/users", RqHeaders: datatypes.JSON(`[{"name":"X-A","match":"present"}]`)},
+			b:    db.Mock{ID: 1, RqPath: "/users"},
+			want: true,
+		},
+		{
+			name: "lower id wins on tie",
+			a:    db.Mock{ID: 1, RqPath: "/users"},
+			b:    db.Mock{ID: 2, RqPath: "/users"},
+			want: true,
+		},
+		{
+			name: "higher id loses on tie",
+			a:    db.Mock{ID: 2, RqPath: "/users"},
+			b:    db.Mock{ID: 1, RqPath: "/users"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := mustCompile(t, tt.a), mustCompile(t, tt.b)
+			if got := a.better(b); got != tt.want {
+				t.Errorf("better() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchMock(t *testing.T) {
+	mocks := []db.Mock{
+		{ID: 1, RqPathRegex: "^/users/(?P<id>[0-9]+)$"},
+		{ID: 2, RqPath: "/users/{id}"},
+		{ID: 3, RqPath: "/users/7"},
+		{ID: 4, RqPath: "/users/{id}", RqQueryParams: datatypes.JSON(`{"full":["1"]}`)},
+		{ID: 5, RqPath: "/orders", Priority: -1},
+		{ID: 6, RqPath: "/orders"},
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantID     int
+		wantParams map[string]string
+	}{
+		{name: "exact wins", target: "/users/7", wantID: 3, wantParams: map[string]string{}},
+		{name: "template over regex", target: "/users/8", wantID: 2, wantParams: map[string]string{"id": "8"}},
+		{name: "more matchers", target: "/users/8?full=1", wantID: 4, wantParams: map[string]string{"id": "8"}},
+		{name: "regex only", target: "/users/8/", wantID: 0},
+		{name: "priority", target: "/orders", wantID: 6, wantParams: map[string]string{}},
+		{name: "no match", target: "/items", wantID: 0},
+	}
+
+	compiled := make([]*compiledMock, 0, len(mocks))
+	for _, m := range mocks {
+		compiled = append(compiled, mustCompile(t, m))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, params := matchMock(compiled, newMatchRQ(http.MethodGet, tt.target, "", http.Header{}))
+			if tt.wantID == 0 {
+				if cm != nil {
+					t.Fatalf("matchMock() = mock [%d], want none", cm.mock.ID)
+				}
+
+				return
+			}
+
+			if cm == nil || cm.mock.ID != tt.wantID {
+				t.Fatalf("matchMock() = %v, want mock [%d]", cm, tt.wantID)
+			}
+
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("matchMock() params = %v, want %v", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestMatchMockSkipsExhaustedSequence(t *testing.T) {
+	first := mustCompile(t, db.Mock{
+		ID:             1,
+		Priority:       1,
+		RqPath:         "/a",
+		RsSequence:     datatypes.JSON(`[{"status":201}]`),
+		RsSequenceMode: db.SequenceFallThrough,
+	})
+	second := mustCompile(t, db.Mock{ID: 2, RqPath: "/a"})
+	mocks := []*compiledMock{first, second}
+	rq := newMatchRQ(http.MethodGet, "/a", "", http.Header{})
+
+	cm, _ := matchMock(mocks, rq)
+	if cm != first {
+		t.Fatalf("matchMock() = %v, want first mock", cm)
+	}
+
+	if _, ok := cm.nextResponse(); !ok {
+		t.Fatal("nextResponse() ok = false, want true")
+	}
+
+	if _, ok := cm.nextResponse(); ok {
+		t.Fatal("nextResponse() ok = true, want false for exhausted sequence")
+	}
+
+	if cm, _ := matchMock(mocks, rq); cm != second {
+		t.Errorf("matchMock() = %v, want second mock", cm)
+	}
+
+	if cm, _ := matchMock(withoutMock(mocks, second), rq); cm != nil {
+		t.Errorf("matchMock() = %v, want none", cm)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		mock       db.Mock
+		path       string
+		wantParams map[string]string
+		wantDiff   bool
+	}{
+		{name: "exact", mock: db.Mock{RqPath: "/a/b"}, path: "/a/b", wantParams: map[string]string{}},
+		{name: "exact differs", mock: db.Mock{RqPath: "/a/b"}, path: "/a/c", wantDiff: true},
+		{name: "template", mock: db.Mock{RqPath: "/a/{x}/c/{y}"}, path: "/a/1/c/2", wantParams: map[string]string{"x": "1", "y": "2"}},
+		{name: "template differs", mock: db.Mock{RqPath: "/a/{x}"}, path: "/b/1", wantDiff: true},
+		{name: "regex named groups", mock: db.Mock{RqPathRegex: "^/a/(?P<x>[a-z]+)/([0-9]+)$"}, path: "/a/q/1", wantParams: map[string]string{"x": "q"}},
+		{name: "regex differs", mock: db.Mock{RqPathRegex: "^/a/[0-9]+$"}, path: "/a/q", wantDiff: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, diff := mustCompile(t, tt.mock).matchPath(tt.path)
+			if (diff != "") != tt.wantDiff {
+				t.Fatalf("matchPath() diff = %q, wantDiff %v", diff, tt.wantDiff)
+			}
+
+			if !tt.wantDiff && !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("matchPath() params = %v, want %v", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestMatchQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		mock      db.Mock
+		query     string
+		wantDiffs int
+	}{
+		{name: "exact", mock: db.Mock{RqQueryParams: datatypes.JSON(`{"a":["1"]}`)}, query: "a=1"},
+		{name: "exact extra param", mock: db.Mock{RqQueryParams: datatypes.JSON(`{"a":["1"]}`)}, query: "a=1&b=2", wantDiffs: 1},
+		{name: "exact extra value", mock: db.Mock{RqQueryParams: datatypes.JSON(`{"a":["1"]}`)}, query: "a=1&a=2", wantDiffs: 1},
+		{name: "subset extra param", mock: db.Mock{RqQueryParams: datatypes.JSON(`{"a":["1"]}`), RqQueryMatch: db.QueryMatchSubset}, query: "a=1&b=2"},
+		{name: "missing param", mock: db.Mock{RqQueryParams: datatypes.JSON(`{"a":["1"]}`)}, query: "", wantDiffs: 1},
+		{name: "no params in exact mode", mock: db.Mock{}, query: "a=1", wantDiffs: 1},
+		{name: "regex rule", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"id","match":"regex","value":"^[0-9]+$"}]`)}, query: "id=x&id=12"},
+		{name: "regex rule fails", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"id","match":"regex","value":"^[0-9]+$"}]`)}, query: "id=x", wantDiffs: 1},
+		{name: "wildcard rule", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"q","match":"wildcard","value":"ab*.?"}]`)}, query: "q=abcd.e"},
+		{name: "wildcard rule fails", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"q","match":"wildcard","value":"ab*.?"}]`)}, query: "q=abcd_e", wantDiffs: 1},
+		{name: "present rule", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"q","match":"present"}]`)}, query: "q="},
+		{name: "present rule fails", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"q","match":"present"}]`)}, query: "", wantDiffs: 1},
+		{name: "absent rule", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"q","match":"absent"}]`)}, query: ""},
+		{name: "absent rule fails", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"q","match":"absent"}]`)}, query: "q=1", wantDiffs: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			if diffs := mustCompile(t, tt.mock).matchQuery(query); len(diffs) != tt.wantDiffs {
+				t.Errorf("matchQuery() = %v, want %d diffs", diffs, tt.wantDiffs)
+			}
+		})
+	}
+}
+
+func TestMatchHeaders(t *testing.T) {
+	headers := http.Header{"Content-Type": {"application/JSON; charset=utf-8"}, "X-Token": {"abc-123"}}
+
+	tests := []struct {
+		name  string
+		rule  string
+		match bool
+	}{
+		{name: "equals", rule: `{"name":"x-token","match":"equals","value":"abc-123"}`, match: true},
+		{name: "equals case differs", rule: `{"name":"X-Token","match":"equals","value":"ABC-123"}`},
+		{name: "equals ignore case", rule: `{"name":"X-Token","match":"equals","value":"ABC-123","ignore_case":true}`, match: true},
+		{name: "contains", rule: `{"name":"Content-Type","match":"contains","value":"JSON"}`, match: true},
+		{name: "contains ignore case", rule: `{"name":"Content-Type","match":"contains","value":"json","ignore_case":true}`, match: true},
+		{name: "contains fails", rule: `{"name":"Content-Type","match":"contains","value":"xml"}`},
+		{name: "regex", rule: `{"name":"X-Token","match":"regex","value":"^[a-z]+-[0-9]+$"}`, match: true},
+		{name: "regex ignore case", rule: `{"name":"X-Token","match":"regex","value":"^ABC","ignore_case":true}`, match: true},
+		{name: "present", rule: `{"name":"X-Token","match":"present"}`, match: true},
+		{name: "present fails", rule: `{"name":"X-Other","match":"present"}`},
+		{name: "absent", rule: `{"name":"X-Other","match":"absent"}`, match: true},
+		{name: "absent fails", rule: `{"name":"X-Token","match":"absent"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := mustCompile(t, db.Mock{RqHeaders: datatypes.JSON("[" + tt.rule + "]")})
+			if diffs := cm.matchHeaders(headers); (len(diffs) == 0) != tt.match {
+				t.Errorf("matchHeaders() = %v, want match %v", diffs, tt.match)
+			}
+		})
+	}
+}
+
+func TestMatchBody(t *testing.T) {
+	tests := []struct {
+		name  string
+		mock  db.Mock
+		body  string
+		match bool
+	}{
+		{name: "empty mock body matches any", mock: db.Mock{}, body: "x", match: true},
+		{name: "raw", mock: db.Mock{RqBody: `{"a":1}`}, body: `{"a":1}`, match: true},
+		{name: "raw differs by spaces", mock: db.Mock{RqBody: `{"a":1}`}, body: `{"a": 1}`},
+		{name: "json ignores key order", mock: db.Mock{RqBody: `{"a":1,"b":2}`, RqBodyMatch: db.BodyMatchJSON}, body: `{"b":2, "a":1}`, match: true},
+		{name: "json extra key", mock: db.Mock{RqBody: `{"a":1}`, RqBodyMatch: db.BodyMatchJSON}, body: `{"a":1,"b":2}`},
+		{name: "json contains extra key", mock: db.Mock{RqBody: `{"a":1}`, RqBodyMatch: db.BodyMatchJSONContains}, body: `{"a":1,"b":2}`, match: true},
+		{name: "json invalid request body", mock: db.Mock{RqBody: `{"a":1}`, RqBodyMatch: db.BodyMatchJSON}, body: `{"a":`},
+		{name: "predicate", mock: db.Mock{RqBodyPredicates: datatypes.JSON(`["$.user.age >= 18","$.tags"]`)}, body: `{"user":{"age":20},"tags":[]}`, match: true},
+		{name: "predicate fails", mock: db.Mock{RqBodyPredicates: datatypes.JSON(`["$.user.age >= 18"]`)}, body: `{"user":{"age":17}}`},
+		{name: "predicate invalid request body", mock: db.Mock{RqBodyPredicates: datatypes.JSON(`["$.a"]`)}, body: `a`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock.RqPath = "/a"
+			cm := mustCompile(t, tt.mock)
+			if _, ok := cm.match(newMatchRQ(http.MethodPost, "/a", tt.body, http.Header{})); ok != tt.match {
+				t.Errorf("match() = %v, want %v", ok, tt.match)
+			}
+		})
+	}
+}
+
+func TestCompileMockErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		mock db.Mock
+	}{
+		{name: "bad path regex", mock: db.Mock{RqPathRegex: "("}},
+		{name: "bad template", mock: db.Mock{RqPath: "/a/{x", RqPathMatch: db.PathMatchTemplate}},
+		{name: "bad json body", mock: db.Mock{RqBody: "{", RqBodyMatch: db.BodyMatchJSON}},
+		{name: "bad predicate", mock: db.Mock{RqBodyPredicates: datatypes.JSON(`["a == 1"]`)}},
+		{name: "bad query rule regex", mock: db.Mock{RqQueryRules: datatypes.JSON(`[{"key":"a","match":"regex","value":"("}]`)}},
+		{name: "bad header regex", mock: db.Mock{RqHeaders: datatypes.JSON(`[{"name":"a","match":"regex","value":"("}]`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := compileMock(tt.mock); err == nil {
+				t.Error("compileMock() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	cm := mustCompile(t, db.Mock{
+		RqMethod:  http.MethodPost,
+		RqPath:    "/users/{id}",
+		RqBody:    `{"a":1}`,
+		RqHeaders: datatypes.JSON(`[{"name":"X-Token","match":"present"}]`),
+	})
+
+	tests := []struct {
+		name      string
+		rq        *matchRQ
+		wantDiffs []string
+	}{
+		{
+			name: "matches",
+			rq:   newMatchRQ(http.MethodPost, "/users/1", `{"a":1}`, http.Header{"X-Token": {"t"}}),
+		},
+		{
+			name:      "method & header",
+			rq:        newMatchRQ(http.MethodGet, "/users/1", `{"a":1}`, http.Header{}),
+			wantDiffs: []string{"method differs", "header [X-Token] missing"},
+		},
+		{
+			name:      "path & body",
+			rq:        newMatchRQ(http.MethodPost, "/orders/1", `{"a":2}`, http.Header{"X-Token": {"t"}}),
+			wantDiffs: []string{"path differs", "body differs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := cm.explain(tt.rq)
+			if len(diffs) != len(tt.wantDiffs) {
+				t.Fatalf("explain() = %v, want %v", diffs, tt.wantDiffs)
+			}
+
+			for i, want := range tt.wantDiffs {
+				if !strings.HasPrefix(diffs[i], want) {
+					t.Errorf("explain()[%d] = %q, want prefix %q", i, diffs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPathParams(t *testing.T) {
+	cm := mustCompile(t, db.Mock{RqPath: "/users/{id}/*"})
+	params, ok := cm.template.Match("/users/1/a/b")
+	if !ok {
+		t.Fatal("Match() ok = false, want true")
+	}
+
+	r, _ := http.NewRequest(http.MethodGet, "/users/1/a/b", nil)
+	if got := getPathParams(r); got != nil {
+		t.Errorf("getPathParams() = %v, want nil without matched mock", got)
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), pathParamsKey, params))
+	want := map[string]string{"id": "1", "*": "a/b"}
+	if got := getPathParams(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("getPathParams() = %v, want %v", got, want)
+	}
+}
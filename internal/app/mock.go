@@ -4,18 +4,21 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strings"
 
 	"encoding/json"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
-	"github.com/mmiloslav/mock/internal/db"
 	"github.com/mmiloslav/mock/internal/myerrors"
 	"github.com/mmiloslav/mock/internal/mylog"
 	"github.com/mmiloslav/mock/pkg/stringtool"
 )
 
-const requestIDKey = "request_id"
+const (
+	requestIDKey  = "request_id"
+	pathParamsKey = "path_params"
+)
 
 // NewRouter creates mux.Router
 func NewRouter() http.Handler {
@@ -25,7 +28,7 @@ func NewRouter() http.Handler {
 		Methods([]string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace}...).
 		Path("/{rest:.*}").
 		Name("Mock").
-		Handler(requestIDMiddleware(http.HandlerFunc(mockHandler)))
+		Handler(requestIDMiddleware(journalMiddleware(http.HandlerFunc(mockHandler))))
 
 	return http.Handler(router)
 }
@@ -68,6 +71,11 @@ func (rs *baseRS) setError(err string) {
 	rs.ErrorCode = err
 }
 
+type notFoundRS struct {
+	baseRS
+	NearMisses []nearMiss `json:"near_misses,omitempty"`
+}
+
 func mockHandler(w http.ResponseWriter, r *http.Request) {
 	logger := mylog.Logger.WithField(requestIDKey, r.Context().Value(requestIDKey))
 	logger.Info("mocking response...")
@@ -87,25 +95,112 @@ func mockHandler(w http.ResponseWriter, r *http.Request) {
 		body = string(bodyBytes)
 	}
 
-	mockDB, err := db.GetMock(r.Method, r.URL.Path, body, r.URL.Query())
+	entry := getJournalEntry(r)
+	entry.Body = body
+
+	rq := &matchRQ{method: r.Method, path: r.URL.Path, body: body, query: r.URL.Query(), headers: r.Header}
+	candidates := index.candidates(r.Method, r.URL.Path)
+
+	// response is claimed after match, concurrent request may exhaust fall through sequence
+	// or move scenario in between, then request is matched again without that mock
+	var cm *compiledMock
+	var pathParams map[string]string
+	var response compiledResponse
+	for {
+		cm, pathParams = matchMock(candidates, rq)
+		if cm == nil {
+			misses := index.nearMisses(rq, nearMissLimit)
+			logger.Errorf("mock not found for [%s %s]", r.Method, r.URL.String())
+			for _, miss := range misses {
+				logger.Errorf("near miss mock [%d] [%s]: %s", miss.MockID, miss.Name, strings.Join(miss.Diffs, "; "))
+			}
+
+			writeFallback(w, r, body, misses, logger)
+			return
+		}
+
+		mockDB := cm.mock
+		logger.Infof("matched mock [%d] with path params %v", mockDB.ID, pathParams)
+		entry.MockID, entry.GroupID = mockDB.ID, mockDB.GroupID
+
+		// spec is checked before response is claimed, so rejected request does not move sequence or scenario
+		if s, ok := getEnabledSpec(mockDB.GroupID); ok {
+			violations := s.validateRequest(r, body)
+			if len(violations) > 0 {
+				logger.Errorf("request violates openapi spec of group [%d]: %s", mockDB.GroupID, strings.Join(violations, "; "))
+				writeSpecViolations(w, s, violations)
+				return
+			}
+		}
+
+		var ok bool
+		response, ok = cm.claimResponse()
+		if ok {
+			break
+		}
+
+		logger.Infof("sequence or scenario of mock [%d] got changed by concurrent request, matching again", mockDB.ID)
+		candidates = withoutMock(candidates, cm)
+		entry.MockID, entry.GroupID = 0, 0
+	}
+
+	mockDB := cm.mock
+	if cm.totalWeight > 0 {
+		entry.Variant = &response.index
+		logger = logger.WithField("variant", response.index)
+		logger.Infof("picked variant [%d] with status [%d] of mock [%d]", response.index, response.status, mockDB.ID)
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), pathParamsKey, pathParams))
+
+	headers, rsBody, err := response.render(r, body, getPathParams(r))
 	if err != nil {
-		logger.Errorf("failed to find mock with error [%s]", err.Error())
+		logger.Errorf("failed to render mock [%d] response with error [%s]", mockDB.ID, err.Error())
 		rs.setError(myerrors.ErrInternal)
 		writeResponse(w, rs, http.StatusInternalServerError)
 		return
 	}
-	if mockDB.ID == 0 {
-		logger.Errorf("mock not found")
-		rs.setError(myerrors.ErrNotFound)
-		writeResponse(w, rs, http.StatusNotFound)
-		return
+
+	status, fault, faultBytes := response.status, mockDB.RsFault, mockDB.RsFaultBytes
+	delay := getDelay(mockDB)
+
+	outcome := evalChaos(mockDB.GroupID)
+	if outcome.applied() {
+		logger.Infof("chaos of group [%d] applied: delay [%s], status [%d], fault [%s]", mockDB.GroupID, outcome.delay, outcome.status, outcome.fault)
+		delay += outcome.delay
+
+		if outcome.status > 0 {
+			status, headers, rsBody, fault = outcome.status, nil, outcome.body, ""
+		}
+
+		if outcome.fault != "" {
+			fault, faultBytes = outcome.fault, 0
+		}
 	}
 
-	headers, err := mockDB.GetRsHeaders()
+	if delay > 0 {
+		logger.Infof("delaying mock [%d] response for [%s]", mockDB.ID, delay)
+	}
+
+	err = sleep(r.Context(), delay)
 	if err != nil {
-		logger.Errorf("failed to get mock [%d] rs headers with error [%s]", mockDB.ID, err.Error())
-		rs.setError(myerrors.ErrInternal)
-		writeResponse(w, rs, http.StatusInternalServerError)
+		logger.Errorf("request cancelled while delaying mock [%d] response with error [%s]", mockDB.ID, err.Error())
+		return
+	}
+
+	if !stringtool.Empty(fault) {
+		logger.Infof("injecting [%s] fault for mock [%d]", fault, mockDB.ID)
+		entry.Fault = fault
+		err = writeFault(w, fault, status, headers, rsBody, faultBytes)
+		if err != nil {
+			logger.Errorf("failed to inject fault for mock [%d] with error [%s]", mockDB.ID, err.Error())
+		}
+
+		return
+	}
+
+	if cm.proxy != nil && outcome.status == 0 {
+		proxyRequest(w, r, cm.proxy, body, logger)
 		return
 	}
 
@@ -115,9 +210,9 @@ func mockHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	w.WriteHeader(mockDB.RsStatus)
-	if !stringtool.Empty(mockDB.RsBody) {
-		_, err := w.Write([]byte(mockDB.RsBody))
+	w.WriteHeader(status)
+	if !stringtool.Empty(rsBody) {
+		_, err := w.Write([]byte(rsBody))
 		if err != nil {
 			logger.Errorf("failed to write rs body for mock [%d] with error [%s]", mockDB.ID, err.Error())
 			rs.setError(myerrors.ErrInternal)
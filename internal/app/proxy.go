@@ -0,0 +1,101 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/pkg/stringtool"
+	"github.com/sirupsen/logrus"
+)
+
+// proxyTarget is a parsed proxy base url with request changes
+type proxyTarget struct {
+	url         *url.URL
+	opts        db.ProxyOptions
+	pathPattern *regexp.Regexp
+}
+
+// newProxyTarget parses proxy base url & path rewrite pattern
+func newProxyTarget(target string, opts db.ProxyOptions) (*proxyTarget, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	pt := &proxyTarget{url: targetURL, opts: opts}
+	if !stringtool.Empty(opts.PathPattern) {
+		pt.pathPattern, err = regexp.Compile(opts.PathPattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pt, nil
+}
+
+// rewrite changes outgoing request path & headers according to proxy options
+func (pt *proxyTarget) rewrite(pr *httputil.ProxyRequest) {
+	if pt.pathPattern != nil {
+		pr.Out.URL.Path = pt.pathPattern.ReplaceAllString(pr.Out.URL.Path, pt.opts.PathReplace)
+		pr.Out.URL.RawPath = ""
+	}
+
+	pr.SetURL(pt.url)
+	pr.SetXForwarded()
+
+	for _, k := range pt.opts.HeadersRemove {
+		pr.Out.Header.Del(k)
+	}
+
+	for k, vals := range pt.opts.HeadersAdd {
+		for _, v := range vals {
+			pr.Out.Header.Add(k, v)
+		}
+	}
+}
+
+// proxyRequest forwards request to target base url and writes upstream response
+func proxyRequest(w http.ResponseWriter, r *http.Request, target *proxyTarget, body string, logger *logrus.Entry) {
+	r.Body = io.NopCloser(strings.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	recording := isRecording()
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			target.rewrite(pr)
+
+			// let transport negotiate compression so recorded body is plain
+			if recording {
+				pr.Out.Header.Del("Accept-Encoding")
+			}
+		},
+		ModifyResponse: func(rs *http.Response) error {
+			if !recording {
+				return nil
+			}
+
+			err := recordResponse(rs, r, body, logger)
+			if err != nil {
+				logger.Errorf("failed to record response of [%s %s] with error [%s]", r.Method, r.URL.Path, err.Error())
+			}
+
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Errorf("failed to proxy request to [%s] with error [%s]", target.url, err.Error())
+			rs := baseRS{}
+			rs.setError(myerrors.ErrBadGateway)
+			writeResponse(w, rs, http.StatusBadGateway)
+		},
+	}
+
+	logger.Infof("proxying request to [%s]", target.url)
+	proxy.ServeHTTP(w, r)
+}
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/pkg/jsontool"
+	"github.com/sirupsen/logrus"
+)
+
+// Recording is a record mode configuration, proxied traffic is saved as mocks of the group,
+// only listed request & response headers are kept
+type Recording struct {
+	Active    bool     `json:"active"`
+	GroupID   int      `json:"group_id,omitempty"`
+	RqHeaders []string `json:"rq_headers,omitempty"`
+	RsHeaders []string `json:"rs_headers,omitempty"`
+	Recorded  int      `json:"recorded"`
+}
+
+// recorder keeps record mode state, seen keeps dedup keys of group mocks,
+// session tells apart recordings so late saves do not touch the next one.
+// Recorded mocks are saved inactive and kept in pending until recording stops,
+// so proxy mock keeps serving traffic while it is recorded
+var recorder = struct {
+	mu        sync.Mutex
+	recording Recording
+	seen      map[string]struct{}
+	pending   []int
+	session   int
+}{}
+
+// maxMockNameAttempts is a number of suffixes tried to get unique recorded mock name
+const maxMockNameAttempts = 100
+
+// StartRecording starts saving proxied traffic into group, existing group mocks are not recorded again,
+// mocks of previous recording are activated
+func StartRecording(groupID int, rqHeaders, rsHeaders []string) error {
+	mocks, err := db.GetGroupMocks(groupID)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(mocks))
+	for _, m := range mocks {
+		if m.GetPathMatch() != db.PathMatchExact {
+			continue
+		}
+
+		query, err := m.GetRqQueryParams()
+		if err != nil {
+			return err
+		}
+
+		seen[recordKey(m.RqMethod, m.RqPath, query, m.RqBody)] = struct{}{}
+	}
+
+	recorder.mu.Lock()
+	pending := recorder.pending
+	recorder.recording = Recording{Active: true, GroupID: groupID, RqHeaders: rqHeaders, RsHeaders: rsHeaders}
+	recorder.seen = seen
+	recorder.pending = nil
+	recorder.session++
+	recorder.mu.Unlock()
+
+	return activateRecorded(pending)
+}
+
+// StopRecording stops record mode, activates recorded mocks and gets final state
+func StopRecording() (Recording, error) {
+	recorder.mu.Lock()
+	recording, pending := recorder.recording, recorder.pending
+	recorder.recording = Recording{Recorded: recording.Recorded}
+	recorder.seen = nil
+	recorder.pending = nil
+	recorder.mu.Unlock()
+
+	return recording, activateRecorded(pending)
+}
+
+// activateRecorded activates recorded mocks in db & index
+func activateRecorded(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	mocks, err := db.ActivateMocks(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range mocks {
+		err = UpsertMock(m)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRecording gets record mode state
+func GetRecording() Recording {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	return recorder.recording
+}
+
+// isRecording checks if record mode is on
+func isRecording() bool {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	return recorder.recording.Active
+}
+
+// recordKey gets dedup key of request by method, path, query & body
+func recordKey(method, path string, query url.Values, body string) string {
+	return strings.Join([]string{method, path, query.Encode(), body}, "\n")
+}
+
+// MockName gets generated mock name, requests with same dedup key get same name
+func MockName(method, path string, query url.Values, body string) string {
+	h := fnv.New32a()
+	h.Write([]byte(recordKey(method, path, query, body)))
+
+	return fmt.Sprintf("%s %s [%08x]", method, path, h.Sum32())
+}
+
+// recordResponse saves upstream response of proxied request as new mock, body is read & restored
+func recordResponse(rs *http.Response, r *http.Request, body string, logger *logrus.Entry) error {
+	rsBody, err := io.ReadAll(rs.Body)
+	if err != nil {
+		return err
+	}
+
+	rs.Body.Close()
+	rs.Body = io.NopCloser(bytes.NewReader(rsBody))
+
+	if r.Method == http.MethodGet {
+		body = ""
+	}
+
+	// dedup key is reserved under lock, db insert & index update run without it
+	key := recordKey(r.Method, r.URL.Path, r.URL.Query(), body)
+
+	recorder.mu.Lock()
+	if !recorder.recording.Active {
+		recorder.mu.Unlock()
+		return nil
+	}
+
+	if _, ok := recorder.seen[key]; ok {
+		recorder.mu.Unlock()
+		return nil
+	}
+
+	recorder.seen[key] = struct{}{}
+	recording, session := recorder.recording, recorder.session
+	recorder.mu.Unlock()
+
+	m, err := newRecordedMock(recording, r, body, rs, string(rsBody))
+	if err == nil {
+		m.Name, err = uniqueMockName(m.Name, m.GroupID)
+	}
+	if err == nil {
+		err = m.Create()
+	}
+	if err != nil {
+		releaseRecordKey(session, key)
+		return err
+	}
+
+	logger.Infof("recorded mock [%d] [%s] into group [%d]", m.ID, m.Name, m.GroupID)
+
+	recorder.mu.Lock()
+	if recorder.session == session && recorder.recording.Active {
+		recorder.recording.Recorded++
+		recorder.pending = append(recorder.pending, m.ID)
+		recorder.mu.Unlock()
+		return nil
+	}
+	recorder.mu.Unlock()
+
+	// recording stopped while mock was saved
+	return activateRecorded([]int{m.ID})
+}
+
+// uniqueMockName gets name not used by other mocks of group, numeric suffix is added on collision
+func uniqueMockName(name string, groupID int) (string, error) {
+	candidate := name
+	for i := 2; i <= maxMockNameAttempts; i++ {
+		exists, err := db.MockExists(candidate, groupID)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return candidate, nil
+		}
+
+		candidate = fmt.Sprintf("%s #%d", name, i)
+	}
+
+	return "", fmt.Errorf("failed to get unique name for mock [%s] in group [%d]", name, groupID)
+}
+
+// releaseRecordKey removes reserved dedup key after failed save, so request may be recorded again
+func releaseRecordKey(session int, key string) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.session == session && recorder.seen != nil {
+		delete(recorder.seen, key)
+	}
+}
+
+// newRecordedMock builds inactive mock from proxied request & upstream response
+func newRecordedMock(recording Recording, r *http.Request, body string, rs *http.Response, rsBody string) (db.Mock, error) {
+	m := db.Mock{
+		Name:        MockName(r.Method, r.URL.Path, r.URL.Query(), body),
+		GroupID:     recording.GroupID,
+		RqMethod:    r.Method,
+		RqPath:      r.URL.Path,
+		RqPathMatch: db.PathMatchExact,
+		RqBody:      body,
+		RsStatus:    rs.StatusCode,
+		RsBody:      rsBody,
+	}
+
+	if body != "" && jsontool.Valid(body) {
+		m.RqBodyMatch = db.BodyMatchJSON
+	}
+
+	var err error
+	if query := r.URL.Query(); len(query) > 0 {
+		m.RqQueryParams, err = json.Marshal(query)
+		if err != nil {
+			return db.Mock{}, err
+		}
+	}
+
+	var rqHeaders []db.HeaderMatcher
+	for _, name := range recording.RqHeaders {
+		if v := r.Header.Get(name); v != "" {
+			rqHeaders = append(rqHeaders, db.HeaderMatcher{Name: name, Match: db.HeaderMatchEquals, Value: v})
+		}
+	}
+
+	if len(rqHeaders) > 0 {
+		m.RqHeaders, err = json.Marshal(rqHeaders)
+		if err != nil {
+			return db.Mock{}, err
+		}
+	}
+
+	rsHeaders := map[string][]string{}
+	for _, name := range recording.RsHeaders {
+		if vals := rs.Header.Values(name); len(vals) > 0 {
+			rsHeaders[http.CanonicalHeaderKey(name)] = vals
+		}
+	}
+
+	m.RsHeaders, err = json.Marshal(rsHeaders)
+	if err != nil {
+		return db.Mock{}, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/pkg/templatetool"
+)
+
+// compiledResponse is a mock response with parsed templates
+type compiledResponse struct {
+	index   int
+	weight  int
+	status  int
+	headers map[string][]string
+	body    string
+	bodyTpl *templatetool.Template
+	hdrTpls map[string][]*templatetool.Template
+}
+
+// compileResponses gets mock responses, sequence or weighted variants replace single response if set
+func compileResponses(m db.Mock) ([]compiledResponse, error) {
+	sequence, err := m.GetRsSequence()
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := m.GetRsVariants()
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make([]int, 0, len(variants))
+	for _, v := range variants {
+		sequence = append(sequence, v.SequenceResponse)
+		weights = append(weights, v.Weight)
+	}
+
+	if len(sequence) == 0 {
+		headers, err := m.GetRsHeaders()
+		if err != nil {
+			return nil, err
+		}
+
+		sequence = []db.SequenceResponse{{Status: m.RsStatus, Headers: headers, Body: m.RsBody}}
+	}
+
+	responses := make([]compiledResponse, 0, len(sequence))
+	for i, rs := range sequence {
+		cr, err := compileResponse(rs, m.RsTemplate)
+		if err != nil {
+			return nil, err
+		}
+
+		cr.index = i
+		if len(weights) > 0 {
+			cr.weight = weights[i]
+		}
+
+		responses = append(responses, cr)
+	}
+
+	return responses, nil
+}
+
+// compileResponse parses response body & header values templates
+func compileResponse(rs db.SequenceResponse, template bool) (compiledResponse, error) {
+	cr := compiledResponse{status: rs.Status, headers: rs.Headers, body: rs.Body}
+	if !template {
+		return cr, nil
+	}
+
+	var err error
+	cr.bodyTpl, err = templatetool.Parse(rs.Body)
+	if err != nil {
+		return compiledResponse{}, err
+	}
+
+	cr.hdrTpls = make(map[string][]*templatetool.Template, len(rs.Headers))
+	for k, vals := range rs.Headers {
+		for _, v := range vals {
+			tpl, err := templatetool.Parse(v)
+			if err != nil {
+				return compiledResponse{}, err
+			}
+
+			cr.hdrTpls[k] = append(cr.hdrTpls[k], tpl)
+		}
+	}
+
+	return cr, nil
+}
+
+// render gets response headers & body, templates are rendered with request data
+func (cr compiledResponse) render(r *http.Request, body string, pathParams map[string]string) (map[string][]string, string, error) {
+	if cr.bodyTpl == nil {
+		return cr.headers, cr.body, nil
+	}
+
+	rq := templatetool.Request{
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		PathParams: pathParams,
+		Query:      r.URL.Query(),
+		Headers:    r.Header,
+		Body:       body,
+	}
+
+	headers := make(map[string][]string, len(cr.hdrTpls))
+	for k, tpls := range cr.hdrTpls {
+		for _, tpl := range tpls {
+			v, err := tpl.Execute(rq)
+			if err != nil {
+				return nil, "", err
+			}
+
+			headers[k] = append(headers[k], v)
+		}
+	}
+
+	rsBody, err := cr.bodyTpl.Execute(rq)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return headers, rsBody, nil
+}
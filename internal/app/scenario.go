@@ -0,0 +1,138 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/mylog"
+)
+
+// scenarios keeps current state of every scenario by name, memory is the source of truth on mock port
+// and transitions are persisted to db in background in the order they were made
+var scenarios = struct {
+	mu     sync.RWMutex
+	states map[string]string
+}{states: map[string]string{}}
+
+const scenarioWritesSize = 1024
+
+// scenarioTransition is a scenario state change waiting to be persisted
+type scenarioTransition struct {
+	name string
+	from string
+	to   string
+}
+
+var (
+	scenarioWrites      = make(chan scenarioTransition, scenarioWritesSize)
+	scenarioWritesStart sync.Once
+)
+
+// LoadScenarios loads all scenario states from db
+func LoadScenarios() error {
+	dbScenarios, err := db.GetScenarios()
+	if err != nil {
+		return err
+	}
+
+	states := make(map[string]string, len(dbScenarios))
+	for _, s := range dbScenarios {
+		states[s.Name] = s.State
+	}
+
+	scenarios.mu.Lock()
+	defer scenarios.mu.Unlock()
+
+	scenarios.states = states
+
+	scenarioWritesStart.Do(func() {
+		go persistScenarios()
+	})
+
+	return nil
+}
+
+// persistScenarios saves scenario transitions to db one by one
+func persistScenarios() {
+	for t := range scenarioWrites {
+		err := db.SetScenarioState(t.name, t.from, t.to)
+		if err != nil {
+			mylog.Logger.Errorf("failed to move scenario [%s] from state [%s] to [%s] with error [%s]", t.name, t.from, t.to, err.Error())
+		}
+	}
+}
+
+// SetScenarioState sets scenario state in memory
+func SetScenarioState(name, state string) {
+	scenarios.mu.Lock()
+	defer scenarios.mu.Unlock()
+
+	scenarios.states[name] = state
+}
+
+// RemoveScenario removes scenario from memory
+func RemoveScenario(name string) {
+	scenarios.mu.Lock()
+	defer scenarios.mu.Unlock()
+
+	delete(scenarios.states, name)
+}
+
+// scenarioState gets current scenario state, unknown scenario is in started state
+func scenarioState(name string) string {
+	scenarios.mu.RLock()
+	defer scenarios.mu.RUnlock()
+
+	state, ok := scenarios.states[name]
+	if !ok {
+		return db.ScenarioStarted
+	}
+
+	return state
+}
+
+// claimResponse claims mock response and moves mock scenario to new state at once under scenario lock,
+// false if scenario left required state or sequence got exhausted since mock was matched
+func (cm *compiledMock) claimResponse() (compiledResponse, bool) {
+	m := cm.mock
+	if m.ScenarioName == "" {
+		return cm.nextResponse()
+	}
+
+	scenarios.mu.Lock()
+	defer scenarios.mu.Unlock()
+
+	from, ok := scenarios.states[m.ScenarioName]
+	if !ok {
+		from = db.ScenarioStarted
+	}
+
+	if m.ScenarioRequiredState != "" && from != m.ScenarioRequiredState {
+		return compiledResponse{}, false
+	}
+
+	response, ok := cm.nextResponse()
+	if !ok || m.ScenarioNewState == "" {
+		return response, ok
+	}
+
+	scenarios.states[m.ScenarioName] = m.ScenarioNewState
+	scenarioWrites <- scenarioTransition{name: m.ScenarioName, from: from, to: m.ScenarioNewState}
+
+	return response, true
+}
+
+// matchScenario checks scenario is in state required by mock, returns diff if not
+func (cm *compiledMock) matchScenario() string {
+	if cm.mock.ScenarioName == "" || cm.mock.ScenarioRequiredState == "" {
+		return ""
+	}
+
+	state := scenarioState(cm.mock.ScenarioName)
+	if state != cm.mock.ScenarioRequiredState {
+		return fmt.Sprintf("scenario [%s] is in state [%s], expected [%s]", cm.mock.ScenarioName, state, cm.mock.ScenarioRequiredState)
+	}
+
+	return ""
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"math/rand/v2"
+
+	"github.com/mmiloslav/mock/internal/db"
+)
+
+// nextResponse claims response to serve, false if sequence is exhausted in fall through mode
+func (cm *compiledMock) nextResponse() (compiledResponse, bool) {
+	if cm.totalWeight > 0 {
+		return cm.pickVariant(), true
+	}
+
+	if len(cm.responses) == 1 && cm.mock.GetSequenceMode() != db.SequenceFallThrough {
+		return cm.responses[0], true
+	}
+
+	cm.seqMu.Lock()
+	defer cm.seqMu.Unlock()
+
+	n := len(cm.responses)
+	i := cm.seqNext
+	switch cm.mock.GetSequenceMode() {
+	case db.SequenceLoop:
+		cm.seqNext = (cm.seqNext + 1) % n
+	case db.SequenceFallThrough:
+		if i >= n {
+			return compiledResponse{}, false
+		}
+
+		cm.seqNext++
+	default:
+		if cm.seqNext < n-1 {
+			cm.seqNext++
+		}
+	}
+
+	return cm.responses[i], true
+}
+
+// pickVariant picks random response variant according to weights
+func (cm *compiledMock) pickVariant() compiledResponse {
+	n := rand.IntN(cm.totalWeight)
+	for _, rs := range cm.responses {
+		if n < rs.weight {
+			return rs
+		}
+
+		n -= rs.weight
+	}
+
+	return cm.responses[len(cm.responses)-1]
+}
+
+// exhausted checks if fall through sequence has no more responses
+func (cm *compiledMock) exhausted() bool {
+	if cm.mock.GetSequenceMode() != db.SequenceFallThrough {
+		return false
+	}
+
+	cm.seqMu.Lock()
+	defer cm.seqMu.Unlock()
+
+	return cm.seqNext >= len(cm.responses)
+}
+
+// ResetSequence resets mock response sequence to the first response
+func ResetSequence(id int) bool {
+	index.mu.RLock()
+	cm, ok := index.mocks[id]
+	index.mu.RUnlock()
+
+	if !ok {
+		return false
+	}
+
+	cm.seqMu.Lock()
+	defer cm.seqMu.Unlock()
+
+	cm.seqNext = 0
+
+	return true
+}
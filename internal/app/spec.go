@@ -0,0 +1,175 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mmiloslav/mock/internal/db"
+	"github.com/mmiloslav/mock/internal/myerrors"
+	"github.com/mmiloslav/mock/internal/mylog"
+	"github.com/mmiloslav/mock/pkg/openapitool"
+)
+
+// specState is a group spec with its parsed document
+type specState struct {
+	spec db.GroupSpec
+	doc  openapitool.Document
+}
+
+// specs keeps OpenAPI specs by group id
+var specs = struct {
+	mu      sync.RWMutex
+	byGroup map[int]specState
+}{byGroup: map[int]specState{}}
+
+type specViolationRS struct {
+	baseRS
+	Violations []string `json:"violations"`
+}
+
+// LoadSpecs loads all group specs from db
+func LoadSpecs() error {
+	dbSpecs, err := db.GetGroupSpecs()
+	if err != nil {
+		return err
+	}
+
+	byGroup := make(map[int]specState, len(dbSpecs))
+	for _, s := range dbSpecs {
+		doc, err := openapitool.Load([]byte(s.Document))
+		if err != nil {
+			mylog.Logger.Errorf("failed to load openapi spec of group [%d] with error [%s], skipping", s.GroupID, err.Error())
+			continue
+		}
+
+		byGroup[s.GroupID] = specState{spec: s, doc: doc}
+	}
+
+	specs.mu.Lock()
+	defer specs.mu.Unlock()
+
+	specs.byGroup = byGroup
+
+	return nil
+}
+
+// SetSpec adds or replaces group spec
+func SetSpec(s db.GroupSpec) error {
+	doc, err := openapitool.Load([]byte(s.Document))
+	if err != nil {
+		return err
+	}
+
+	specs.mu.Lock()
+	defer specs.mu.Unlock()
+
+	specs.byGroup[s.GroupID] = specState{spec: s, doc: doc}
+
+	return nil
+}
+
+// RemoveSpec removes group spec
+func RemoveSpec(groupID int) {
+	specs.mu.Lock()
+	defer specs.mu.Unlock()
+
+	delete(specs.byGroup, groupID)
+}
+
+// getEnabledSpec gets group spec if validation is enabled
+func getEnabledSpec(groupID int) (specState, bool) {
+	specs.mu.RLock()
+	defer specs.mu.RUnlock()
+
+	s, ok := specs.byGroup[groupID]
+	if !ok || !s.spec.Enabled {
+		return specState{}, false
+	}
+
+	return s, true
+}
+
+// validateRequest checks request against group spec, returns violations
+func (s specState) validateRequest(r *http.Request, body string) []string {
+	mo, pathParams, ok := s.doc.FindOperation(r.Method, r.URL.Path)
+	if !ok {
+		return []string{fmt.Sprintf("operation [%s %s] is not declared", r.Method, r.URL.Path)}
+	}
+
+	return mo.ValidateRequest(pathParams, r.URL.Query(), r.Header, body)
+}
+
+// writeSpecViolations writes response to request violating group spec
+func writeSpecViolations(w http.ResponseWriter, s specState, violations []string) {
+	rs := specViolationRS{Violations: violations}
+	rs.setError(myerrors.ErrRequestNotValid)
+	writeResponse(w, rs, s.spec.GetStatus())
+}
+
+// ValidateMockResponses checks mock responses against enabled spec of its group, returns violations.
+// Proxy & templated mocks, as well as mocks with regex or wildcard path, are not checked
+func ValidateMockResponses(m db.Mock) ([]string, error) {
+	s, ok := getEnabledSpec(m.GroupID)
+	if !ok || m.GetRsType() == db.RsTypeProxy || m.RsTemplate {
+		return nil, nil
+	}
+
+	if m.GetPathMatch() != db.PathMatchExact && m.GetPathMatch() != db.PathMatchTemplate {
+		return nil, nil
+	}
+
+	if strings.Contains(m.RqPath, "*") {
+		return nil, nil
+	}
+
+	// template params of mock path match spec path params as any other segment value
+	mo, _, ok := s.doc.FindOperation(m.RqMethod, m.RqPath)
+	if !ok {
+		return []string{fmt.Sprintf("operation [%s %s] is not declared", m.RqMethod, m.RqPath)}, nil
+	}
+
+	sequence, err := m.GetRsSequence()
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := m.GetRsVariants()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range variants {
+		sequence = append(sequence, v.SequenceResponse)
+	}
+
+	if len(sequence) == 0 {
+		headers, err := m.GetRsHeaders()
+		if err != nil {
+			return nil, err
+		}
+
+		sequence = []db.SequenceResponse{{Status: m.RsStatus, Headers: headers, Body: m.RsBody}}
+	}
+
+	var violations []string
+	for i, rs := range sequence {
+		headers := http.Header{}
+		for k, vals := range rs.Headers {
+			for _, v := range vals {
+				headers.Add(k, v)
+			}
+		}
+
+		for _, v := range mo.ValidateResponse(rs.Status, headers, rs.Body) {
+			if len(sequence) > 1 {
+				v = fmt.Sprintf("response [%d] %s", i, v)
+			}
+
+			violations = append(violations, v)
+		}
+	}
+
+	return violations, nil
+}
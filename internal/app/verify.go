@@ -0,0 +1,34 @@
+package app
+
+import (
+	"net/url"
+
+	"github.com/mmiloslav/mock/internal/db"
+)
+
+// MatchJournal gets journal entries which match mock request matchers, oldest first
+func MatchJournal(m db.Mock) ([]JournalEntry, error) {
+	cm, err := compileMock(m)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []JournalEntry{}
+	for _, e := range GetJournal(JournalFilter{}) {
+		if e.Method != m.RqMethod {
+			continue
+		}
+
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			continue
+		}
+
+		rq := &matchRQ{method: e.Method, path: e.Path, body: e.Body, query: u.Query(), headers: e.Headers}
+		if _, ok := cm.match(rq); ok {
+			result = append(result, e)
+		}
+	}
+
+	return result, nil
+}
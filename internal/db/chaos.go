@@ -0,0 +1,92 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// chaos actions
+const (
+	ChaosStatus = "status"
+	ChaosDelay  = "delay"
+	ChaosFault  = "fault"
+)
+
+// ChaosRule is an action applied to a share of group requests
+type ChaosRule struct {
+	Action      string  `json:"action"`
+	Probability float64 `json:"probability"`
+	Status      int     `json:"status,omitempty"`
+	Body        string  `json:"body,omitempty"`
+	DelayMs     int     `json:"delay_ms,omitempty"`
+	Fault       string  `json:"fault,omitempty"`
+}
+
+// ChaosPolicy is a set of probabilistic faults of group, seed makes runs reproducible.
+// Rules are exclusive, at most one of them fires per request
+type ChaosPolicy struct {
+	ID      int   `gorm:"primaryKey"`
+	GroupID int   `gorm:"uniqueIndex;not null"`
+	Seed    int64 `gorm:"not null;default:0"`
+	Rules   datatypes.JSON
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func GetChaosPolicies() ([]ChaosPolicy, error) {
+	var policies []ChaosPolicy
+	err := mockDB.Order("group_id").Find(&policies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// Save creates or replaces chaos policy of group
+func (m *ChaosPolicy) Save() error {
+	return mockDB.Transaction(func(tx *gorm.DB) error {
+		existing := ChaosPolicy{}
+		err := tx.Where("group_id = ?", m.GroupID).First(&existing).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		m.ID = existing.ID
+		m.CreatedAt = existing.CreatedAt
+
+		return tx.Save(m).Error
+	})
+}
+
+func (m *ChaosPolicy) One() (bool, error) {
+	err := mockDB.Where("group_id = ?", m.GroupID).First(m).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return false, err
+		}
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func DeleteChaosPolicy(groupID int) error {
+	return mockDB.Where("group_id = ?", groupID).Delete(&ChaosPolicy{}).Error
+}
+
+func (m ChaosPolicy) GetRules() ([]ChaosRule, error) {
+	if len(m.Rules) == 0 {
+		return nil, nil
+	}
+
+	var result []ChaosRule
+	err := json.Unmarshal(m.Rules, &result)
+
+	return result, err
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// fallback modes
+const (
+	FallbackNotFound    = "not_found"
+	FallbackCustom      = "custom"
+	FallbackProxy       = "proxy"
+	FallbackDiagnostics = "diagnostics"
+)
+
+// GlobalFallbackGroupID is a group id of global fallback
+const GlobalFallbackGroupID = 0
+
+// Fallback is a behaviour of mock port when no mock matches request
+type Fallback struct {
+	ID       int    `gorm:"primaryKey"`
+	GroupID  int    `gorm:"uniqueIndex;not null"`
+	Mode     string `gorm:"not null"`
+	Status   int
+	Headers  datatypes.JSON
+	Body     string `gorm:"type:text"`
+	ProxyURL string
+	Proxy    datatypes.JSON
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func GetFallbacks() ([]Fallback, error) {
+	var fallbacks []Fallback
+	err := mockDB.Order("group_id").Find(&fallbacks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return fallbacks, nil
+}
+
+// Save creates or replaces fallback of group
+func (m *Fallback) Save() error {
+	return mockDB.Transaction(func(tx *gorm.DB) error {
+		existing := Fallback{}
+		err := tx.Where("group_id = ?", m.GroupID).First(&existing).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		m.ID = existing.ID
+		m.CreatedAt = existing.CreatedAt
+
+		return tx.Save(m).Error
+	})
+}
+
+func (m *Fallback) One() (bool, error) {
+	err := mockDB.Where("group_id = ?", m.GroupID).First(m).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return false, err
+		}
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func DeleteFallback(groupID int) error {
+	return mockDB.Where("group_id = ?", groupID).Delete(&Fallback{}).Error
+}
+
+func (m Fallback) GetHeaders() (map[string][]string, error) {
+	if len(m.Headers) == 0 {
+		return nil, nil
+	}
+
+	var result map[string][]string
+	err := json.Unmarshal(m.Headers, &result)
+
+	return result, err
+}
+
+func (m Fallback) GetProxy() (ProxyOptions, error) {
+	if len(m.Proxy) == 0 {
+		return ProxyOptions{}, nil
+	}
+
+	var result ProxyOptions
+	err := json.Unmarshal(m.Proxy, &result)
+
+	return result, err
+}
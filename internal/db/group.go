@@ -34,12 +34,48 @@ func (m *Group) Create() error {
 	return mockDB.Create(m).Error
 }
 
+// CreateGroupsWithMocks creates new groups and mocks of all groups in one transaction,
+// groups with id are existing ones and only their mocks are created
+func CreateGroupsWithMocks(groups []Group) error {
+	return mockDB.Transaction(func(tx *gorm.DB) error {
+		for i := range groups {
+			group := &groups[i]
+			if group.ID == 0 {
+				if err := tx.Omit("Mocks").Create(group).Error; err != nil {
+					return err
+				}
+			}
+
+			for j := range group.Mocks {
+				group.Mocks[j].GroupID = group.ID
+				if err := tx.Create(&group.Mocks[j]).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
 func (m *Group) Delete() error {
 	return mockDB.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("group_id = ?", m.ID).Delete(&Mock{}).Error; err != nil {
 			return err
 		}
 
+		if err := tx.Where("group_id = ?", m.ID).Delete(&Fallback{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("group_id = ?", m.ID).Delete(&ChaosPolicy{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("group_id = ?", m.ID).Delete(&GroupSpec{}).Error; err != nil {
+			return err
+		}
+
 		if err := tx.Delete(m).Error; err != nil {
 			return err
 		}
@@ -48,6 +84,20 @@ func (m *Group) Delete() error {
 	})
 }
 
+func GetGroupByName(name string) (Group, bool, error) {
+	group := Group{}
+	err := mockDB.Where("name = ?", name).First(&group).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return Group{}, false, nil
+		}
+
+		return Group{}, false, err
+	}
+
+	return group, true, nil
+}
+
 func GroupExistsByName(name string) (bool, error) {
 	group := Group{Name: name}
 
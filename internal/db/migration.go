@@ -10,6 +10,70 @@ var migrations = []*gormigrate.Migration{
 		ID:      "migrate_20250521_initial",
 		Migrate: migrate_20250521_initial,
 	},
+	{
+		ID:      "migrate_20261018_mock_path_match",
+		Migrate: migrate_20261018_mock_path_match,
+	},
+	{
+		ID:      "migrate_20261018_mock_body_match",
+		Migrate: migrate_20261018_mock_body_match,
+	},
+	{
+		ID:      "migrate_20261018_mock_body_predicates",
+		Migrate: migrate_20261018_mock_body_predicates,
+	},
+	{
+		ID:      "migrate_20261018_mock_rq_headers",
+		Migrate: migrate_20261018_mock_rq_headers,
+	},
+	{
+		ID:      "migrate_20261018_mock_query_match",
+		Migrate: migrate_20261018_mock_query_match,
+	},
+	{
+		ID:      "migrate_20261018_mock_priority",
+		Migrate: migrate_20261018_mock_priority,
+	},
+	{
+		ID:      "migrate_20261018_fallback",
+		Migrate: migrate_20261018_fallback,
+	},
+	{
+		ID:      "migrate_20261018_mock_rs_template",
+		Migrate: migrate_20261018_mock_rs_template,
+	},
+	{
+		ID:      "migrate_20261018_mock_rs_delay",
+		Migrate: migrate_20261018_mock_rs_delay,
+	},
+	{
+		ID:      "migrate_20261018_mock_rs_fault",
+		Migrate: migrate_20261018_mock_rs_fault,
+	},
+	{
+		ID:      "migrate_20261018_chaos_policy",
+		Migrate: migrate_20261018_chaos_policy,
+	},
+	{
+		ID:      "migrate_20261018_mock_rs_sequence",
+		Migrate: migrate_20261018_mock_rs_sequence,
+	},
+	{
+		ID:      "migrate_20261018_scenario",
+		Migrate: migrate_20261018_scenario,
+	},
+	{
+		ID:      "migrate_20261018_mock_rs_variants",
+		Migrate: migrate_20261018_mock_rs_variants,
+	},
+	{
+		ID:      "migrate_20261018_rs_proxy",
+		Migrate: migrate_20261018_rs_proxy,
+	},
+	{
+		ID:      "migrate_20261018_group_spec",
+		Migrate: migrate_20261018_group_spec,
+	},
 }
 
 func migrate_20250521_initial(tx *gorm.DB) error {
@@ -18,3 +82,101 @@ func migrate_20250521_initial(tx *gorm.DB) error {
 		&Group{},
 	)
 }
+
+func migrate_20261018_mock_path_match(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_mock_body_match(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_mock_body_predicates(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_mock_rq_headers(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_mock_query_match(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_mock_priority(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_fallback(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Fallback{},
+	)
+}
+
+func migrate_20261018_mock_rs_template(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_mock_rs_delay(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_mock_rs_fault(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_chaos_policy(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&ChaosPolicy{},
+	)
+}
+
+func migrate_20261018_mock_rs_sequence(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_scenario(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Scenario{},
+		&Mock{},
+	)
+}
+
+func migrate_20261018_mock_rs_variants(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+	)
+}
+
+func migrate_20261018_rs_proxy(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&Mock{},
+		&Fallback{},
+	)
+}
+
+func migrate_20261018_group_spec(tx *gorm.DB) error {
+	return tx.AutoMigrate(
+		&GroupSpec{},
+	)
+}
@@ -2,79 +2,337 @@ package db
 
 import (
 	"encoding/json"
-	"errors"
 	"time"
 
+	"github.com/mmiloslav/mock/pkg/pathtool"
 	"github.com/mmiloslav/mock/pkg/stringtool"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// path match types
+const (
+	PathMatchExact    = "exact"
+	PathMatchTemplate = "template"
+	PathMatchRegex    = "regex"
+)
+
+// body match types
+const (
+	BodyMatchRaw          = "raw"
+	BodyMatchJSON         = "json"
+	BodyMatchJSONContains = "json_contains"
+)
+
+// header match types
+const (
+	HeaderMatchEquals   = "equals"
+	HeaderMatchContains = "contains"
+	HeaderMatchRegex    = "regex"
+	HeaderMatchPresent  = "present"
+	HeaderMatchAbsent   = "absent"
+)
+
+// HeaderMatcher is a rule for request header, header name is case-insensitive
+type HeaderMatcher struct {
+	Name       string `json:"name"`
+	Match      string `json:"match"`
+	Value      string `json:"value,omitempty"`
+	IgnoreCase bool   `json:"ignore_case,omitempty"`
+}
+
+// query match types
+const (
+	QueryMatchExact  = "exact"
+	QueryMatchSubset = "subset"
+)
+
+// query rule match types
+const (
+	QueryRuleRegex    = "regex"
+	QueryRuleWildcard = "wildcard"
+	QueryRulePresent  = "present"
+	QueryRuleAbsent   = "absent"
+)
+
+// QueryRule is a rule for request query param values
+type QueryRule struct {
+	Key   string `json:"key"`
+	Match string `json:"match"`
+	Value string `json:"value,omitempty"`
+}
+
+// delay distributions
+const (
+	DelayFixed     = "fixed"
+	DelayUniform   = "uniform"
+	DelayLognormal = "lognormal"
+)
+
+// response faults
+const (
+	FaultConnectionReset  = "connection_reset"
+	FaultEmptyReply       = "empty_reply"
+	FaultMalformedChunked = "malformed_chunked"
+	FaultGarbage          = "garbage"
+	FaultTruncated        = "truncated"
+)
+
+// response sequence modes, when sequence is exhausted
+const (
+	SequenceLoop        = "loop"
+	SequenceStick       = "stick"
+	SequenceFallThrough = "fall_through"
+)
+
+// response types
+const (
+	RsTypeStatic = "static"
+	RsTypeProxy  = "proxy"
+)
+
+// ProxyOptions changes proxied request: headers are removed then added,
+// path matching PathPattern regex is replaced with PathReplace
+type ProxyOptions struct {
+	HeadersAdd    map[string][]string `json:"headers_add,omitempty"`
+	HeadersRemove []string            `json:"headers_remove,omitempty"`
+	PathPattern   string              `json:"path_pattern,omitempty"`
+	PathReplace   string              `json:"path_replace,omitempty"`
+}
+
+// SequenceResponse is one of responses served in turn
+type SequenceResponse struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+// ResponseVariant is one of responses picked randomly according to weight
+type ResponseVariant struct {
+	Weight int `json:"weight"`
+	SequenceResponse
+}
+
 type Mock struct {
-	ID      int    `gorm:"primaryKey"`
-	Name    string `gorm:"not null"`
-	Active  bool   `gorm:"not null"`
-	GroupID int    `gorm:"not null"`
-	Group   Group  `gorm:"not null;foreignKey:GroupID"`
+	ID       int    `gorm:"primaryKey"`
+	Name     string `gorm:"not null"`
+	Active   bool   `gorm:"not null"`
+	GroupID  int    `gorm:"not null"`
+	Group    Group  `gorm:"not null;foreignKey:GroupID"`
+	Priority int    `gorm:"not null;default:0"`
+
+	// SCENARIO
+	ScenarioName          string
+	ScenarioRequiredState string
+	ScenarioNewState      string
 
 	// RQ
-	RqMethod      string `gorm:"not null"`
-	RqPath        string `gorm:"not null"`
-	RqBody        string `gorm:"type:text"`
-	RqQueryParams datatypes.JSON
+	RqMethod         string `gorm:"not null"`
+	RqPath           string `gorm:"not null"`
+	RqPathMatch      string
+	RqPathRegex      string `gorm:"type:text"`
+	RqBody           string `gorm:"type:text"`
+	RqBodyMatch      string
+	RqBodyPredicates datatypes.JSON
+	RqQueryParams    datatypes.JSON
+	RqQueryMatch     string
+	RqQueryRules     datatypes.JSON
+	RqHeaders        datatypes.JSON
 
 	// RS
-	RsStatus  int `gorm:"not null"`
-	RsHeaders datatypes.JSON
-	RsBody    string `gorm:"type:text;not null"`
+	RsType     string
+	RsStatus   int `gorm:"not null"`
+	RsHeaders  datatypes.JSON
+	RsBody     string `gorm:"type:text;not null"`
+	RsTemplate bool   `gorm:"not null;default:false"`
+
+	// RS PROXY
+	RsProxyURL string
+	RsProxy    datatypes.JSON
+
+	// RS SEQUENCE
+	RsSequence     datatypes.JSON
+	RsSequenceMode string
+
+	// RS VARIANTS
+	RsVariants datatypes.JSON
+
+	// RS DELAY
+	RsDelayDistribution string
+	RsDelayMs           int
+	RsDelayMinMs        int
+	RsDelayMaxMs        int
+	RsDelayMedianMs     int
+	RsDelaySigma        float64
+
+	// RS FAULT
+	RsFault      string
+	RsFaultBytes int
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
 }
 
-func GetMock(method, path, body string, queryParams map[string][]string) (Mock, error) {
-	if stringtool.Empty(method) {
-		return Mock{}, errors.New("method id empty")
+// GetActiveMocks gets all active mocks ordered by id
+func GetActiveMocks() ([]Mock, error) {
+	var mocks []Mock
+	err := mockDB.Where(Mock{Active: true}).Order("id").Find(&mocks).Error
+	if err != nil {
+		return nil, err
 	}
 
-	if stringtool.Empty(path) {
-		return Mock{}, errors.New("path id empty")
+	return mocks, nil
+}
+
+// GetGroupMocks gets all mocks of group ordered by id
+func GetGroupMocks(groupID int) ([]Mock, error) {
+	var mocks []Mock
+	err := mockDB.Where("group_id = ?", groupID).Order("id").Find(&mocks).Error
+	if err != nil {
+		return nil, err
 	}
 
-	m := Mock{
-		Active:   true,
-		RqMethod: method,
-		RqPath:   path,
+	return mocks, nil
+}
+
+// GetPathMatch gets path match type, infers it from path for mocks without explicit one
+func (m Mock) GetPathMatch() string {
+	if !stringtool.Empty(m.RqPathMatch) {
+		return m.RqPathMatch
 	}
 
-	tx := mockDB.Where(m)
+	if !stringtool.Empty(m.RqPathRegex) {
+		return PathMatchRegex
+	}
 
-	if len(queryParams) > 0 {
-		jsonBytes, err := json.Marshal(queryParams)
-		if err != nil {
-			return Mock{}, err
-		}
+	if pathtool.IsTemplate(m.RqPath) {
+		return PathMatchTemplate
+	}
+
+	return PathMatchExact
+}
 
-		str := string(jsonBytes)
+// GetBodyMatch gets body match type, raw by default
+func (m Mock) GetBodyMatch() string {
+	if stringtool.Empty(m.RqBodyMatch) {
+		return BodyMatchRaw
+	}
+
+	return m.RqBodyMatch
+}
 
-		tx = tx.Where("JSON_CONTAINS(rq_query_params, ?) AND JSON_CONTAINS(?, rq_query_params)", str, str)
+// GetRsType gets response type, static by default
+func (m Mock) GetRsType() string {
+	if stringtool.Empty(m.RsType) {
+		return RsTypeStatic
 	}
 
-	if body != "" {
-		m.RqBody = body
+	return m.RsType
+}
+
+func (m Mock) GetRsProxy() (ProxyOptions, error) {
+	if len(m.RsProxy) == 0 {
+		return ProxyOptions{}, nil
 	}
 
-	err := tx.First(&m).Error
-	if err != nil {
-		if err != gorm.ErrRecordNotFound {
-			return Mock{}, err
-		}
+	var result ProxyOptions
+	err := json.Unmarshal(m.RsProxy, &result)
+
+	return result, err
+}
+
+// GetDelayDistribution gets delay distribution, fixed by default
+func (m Mock) GetDelayDistribution() string {
+	if stringtool.Empty(m.RsDelayDistribution) {
+		return DelayFixed
+	}
+
+	return m.RsDelayDistribution
+}
 
-		return Mock{}, nil
+// GetSequenceMode gets response sequence mode, stick by default
+func (m Mock) GetSequenceMode() string {
+	if stringtool.Empty(m.RsSequenceMode) {
+		return SequenceStick
 	}
 
-	return m, nil
+	return m.RsSequenceMode
+}
+
+func (m Mock) GetRsSequence() ([]SequenceResponse, error) {
+	if len(m.RsSequence) == 0 {
+		return nil, nil
+	}
+
+	var result []SequenceResponse
+	err := json.Unmarshal(m.RsSequence, &result)
+
+	return result, err
+}
+
+func (m Mock) GetRsVariants() ([]ResponseVariant, error) {
+	if len(m.RsVariants) == 0 {
+		return nil, nil
+	}
+
+	var result []ResponseVariant
+	err := json.Unmarshal(m.RsVariants, &result)
+
+	return result, err
+}
+
+func (m Mock) GetRqBodyPredicates() ([]string, error) {
+	if len(m.RqBodyPredicates) == 0 {
+		return nil, nil
+	}
+
+	var result []string
+	err := json.Unmarshal(m.RqBodyPredicates, &result)
+
+	return result, err
+}
+
+// GetQueryMatch gets query match type, exact by default
+func (m Mock) GetQueryMatch() string {
+	if stringtool.Empty(m.RqQueryMatch) {
+		return QueryMatchExact
+	}
+
+	return m.RqQueryMatch
+}
+
+func (m Mock) GetRqQueryParams() (map[string][]string, error) {
+	if len(m.RqQueryParams) == 0 {
+		return nil, nil
+	}
+
+	var result map[string][]string
+	err := json.Unmarshal(m.RqQueryParams, &result)
+
+	return result, err
+}
+
+func (m Mock) GetRqQueryRules() ([]QueryRule, error) {
+	if len(m.RqQueryRules) == 0 {
+		return nil, nil
+	}
+
+	var result []QueryRule
+	err := json.Unmarshal(m.RqQueryRules, &result)
+
+	return result, err
+}
+
+func (m Mock) GetRqHeaders() ([]HeaderMatcher, error) {
+	if len(m.RqHeaders) == 0 {
+		return nil, nil
+	}
+
+	var result []HeaderMatcher
+	err := json.Unmarshal(m.RqHeaders, &result)
+
+	return result, err
 }
 
 func (m Mock) GetRsHeaders() (map[string][]string, error) {
@@ -92,6 +350,40 @@ func (m *Mock) Create() error {
 	return mockDB.Create(m).Error
 }
 
+// CreateMocks creates all mocks in one transaction, none is created if any fails
+func CreateMocks(mocks []Mock) error {
+	if len(mocks) == 0 {
+		return nil
+	}
+
+	return mockDB.Transaction(func(tx *gorm.DB) error {
+		for i := range mocks {
+			if err := tx.Create(&mocks[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ActivateMocks activates mocks by ids and gets them, deleted mocks are skipped
+func ActivateMocks(ids []int) ([]Mock, error) {
+	var mocks []Mock
+	err := mockDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Mock{}).Where("id IN ?", ids).Update("active", true).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id IN ?", ids).Find(&mocks).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mocks, nil
+}
+
 func (m *Mock) Update() error {
 	return mockDB.Save(m).Error
 }
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScenarioStarted is an initial state of every scenario
+const ScenarioStarted = "Started"
+
+type Scenario struct {
+	ID        int    `gorm:"primaryKey"`
+	Name      string `gorm:"unique;not null"`
+	State     string `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func GetScenarios() ([]Scenario, error) {
+	var scenarios []Scenario
+	err := mockDB.Order("name").Find(&scenarios).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return scenarios, nil
+}
+
+func (m *Scenario) Create() error {
+	return mockDB.Create(m).Error
+}
+
+func (m *Scenario) Update() error {
+	return mockDB.Save(m).Error
+}
+
+func (m *Scenario) Delete() error {
+	return mockDB.Delete(m).Error
+}
+
+func (m *Scenario) One() (bool, error) {
+	err := mockDB.Where(m).First(m).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return false, err
+		}
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// ensureScenario creates scenario in started state if it does not exist
+func ensureScenario(tx *gorm.DB, name string) (Scenario, error) {
+	scenario := Scenario{Name: name}
+	err := tx.Where(Scenario{Name: name}).Attrs(Scenario{State: ScenarioStarted}).FirstOrCreate(&scenario).Error
+
+	return scenario, err
+}
+
+// CreateWithScenario creates mock together with its scenario in one transaction,
+// scenario is created in started state if it does not exist
+func (m *Mock) CreateWithScenario() (Scenario, error) {
+	var scenario Scenario
+	err := mockDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		scenario, err = ensureScenario(tx, m.ScenarioName)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(m).Error
+	})
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	return scenario, nil
+}
+
+// SetScenarioState moves scenario from state to new one, fails if scenario is gone or is not in from state
+func SetScenarioState(name, from, to string) error {
+	result := mockDB.Model(&Scenario{}).Where("name = ? AND state = ?", name, from).Update("state", to)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("scenario [%s] does not exist or is not in state [%s]", name, from)
+	}
+
+	return nil
+}
+
+// ResetScenarios moves all scenarios to started state
+func ResetScenarios() error {
+	return mockDB.Model(&Scenario{}).Where("1 = 1").Update("state", ScenarioStarted).Error
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GroupSpec is an OpenAPI document attached to group, when enabled mock port validates group requests against it
+type GroupSpec struct {
+	ID       int    `gorm:"primaryKey"`
+	GroupID  int    `gorm:"uniqueIndex;not null"`
+	Document string `gorm:"type:longtext;not null"`
+	Enabled  bool   `gorm:"not null;default:false"`
+	// Status is a status of response to request violating spec
+	Status int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func GetGroupSpecs() ([]GroupSpec, error) {
+	var specs []GroupSpec
+	err := mockDB.Order("group_id").Find(&specs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return specs, nil
+}
+
+// Save creates or replaces spec of group
+func (m *GroupSpec) Save() error {
+	return mockDB.Transaction(func(tx *gorm.DB) error {
+		existing := GroupSpec{}
+		err := tx.Where("group_id = ?", m.GroupID).First(&existing).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		m.ID = existing.ID
+		m.CreatedAt = existing.CreatedAt
+
+		return tx.Save(m).Error
+	})
+}
+
+func (m *GroupSpec) One() (bool, error) {
+	err := mockDB.Where("group_id = ?", m.GroupID).First(m).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return false, err
+		}
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func DeleteGroupSpec(groupID int) error {
+	return mockDB.Where("group_id = ?", groupID).Delete(&GroupSpec{}).Error
+}
+
+// GetStatus gets status of response to request violating spec, 400 by default
+func (m GroupSpec) GetStatus() int {
+	if m.Status == 0 {
+		return http.StatusBadRequest
+	}
+
+	return m.Status
+}
@@ -1,12 +1,22 @@
 package myerrors
 
 const (
-	ErrInternal           = "INTERNAL_ERROR"
-	ErrNotFound           = "NOT_FOUND"
-	ErrBadRequest         = "BAD_REQUEST"
-	ErrGroupAlreadyExists = "GROUP_ALREADY_EXISTS"
-	ErrGroupNotExists     = "GROUP_DOES_NOT_EXIST"
-	ErrGroupNotFound      = "GROUP_NOT_FOUND"
-	ErrMockNotExists      = "MOCK_DOES_NOT_EXIST"
-	ErrMockNameExists     = "MOCK_NAME_EXISTS"
+	ErrInternal              = "INTERNAL_ERROR"
+	ErrNotFound              = "NOT_FOUND"
+	ErrBadRequest            = "BAD_REQUEST"
+	ErrGroupAlreadyExists    = "GROUP_ALREADY_EXISTS"
+	ErrGroupNotExists        = "GROUP_DOES_NOT_EXIST"
+	ErrGroupNotFound         = "GROUP_NOT_FOUND"
+	ErrMockNotExists         = "MOCK_DOES_NOT_EXIST"
+	ErrMockNameExists        = "MOCK_NAME_EXISTS"
+	ErrPathRegexNotValid     = "PATH_REGEX_NOT_VALID"
+	ErrBadGateway            = "BAD_GATEWAY"
+	ErrFallbackNotFound      = "FALLBACK_NOT_FOUND"
+	ErrChaosNotFound         = "CHAOS_POLICY_NOT_FOUND"
+	ErrScenarioNotFound      = "SCENARIO_NOT_FOUND"
+	ErrScenarioAlreadyExists = "SCENARIO_ALREADY_EXISTS"
+	ErrSpecNotFound          = "OPENAPI_SPEC_NOT_FOUND"
+	ErrRequestNotValid       = "REQUEST_NOT_VALID"
+	ErrMockResponseNotValid  = "MOCK_RESPONSE_NOT_VALID"
+	ErrMockNotValid          = "MOCK_NOT_VALID"
 )
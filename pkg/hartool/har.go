@@ -0,0 +1,133 @@
+package hartool
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+)
+
+// Version is a HAR spec version written on export
+const Version = "1.2"
+
+// HAR is an HTTP archive document, only fields needed for mocks are kept
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"`
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	Comment         string    `json:"comment,omitempty"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Decode reads HAR document and checks it has log entries
+func Decode(r io.Reader) (HAR, error) {
+	var har HAR
+	err := json.NewDecoder(r).Decode(&har)
+	if err != nil {
+		return HAR{}, err
+	}
+
+	if har.Log.Entries == nil {
+		return HAR{}, errors.New("har log entries are missing")
+	}
+
+	return har, nil
+}
+
+// Body gets decoded response content text
+func (c Content) Body() (string, error) {
+	if c.Encoding != "base64" {
+		return c.Text, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(c.Text)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// NewNameValues converts header or query map to name/value pairs
+func NewNameValues(m map[string][]string) []NameValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := []NameValue{}
+	for _, k := range keys {
+		for _, v := range m[k] {
+			pairs = append(pairs, NameValue{Name: k, Value: v})
+		}
+	}
+
+	return pairs
+}
@@ -0,0 +1,76 @@
+package hartool
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		wantEntries int
+		wantErr     bool
+	}{
+		{
+			name:        "entries",
+			doc:         `{"log":{"version":"1.2","entries":[{"request":{"method":"GET","url":"http://x/a"},"response":{"status":200}}]}}`,
+			wantEntries: 1,
+		},
+		{name: "no entries", doc: `{"log":{"version":"1.2","entries":[]}}`},
+		{name: "missing entries", doc: `{"log":{"version":"1.2"}}`, wantErr: true},
+		{name: "not json", doc: `log`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			har, err := Decode(strings.NewReader(tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && len(har.Log.Entries) != tt.wantEntries {
+				t.Errorf("Decode() entries = %d, want %d", len(har.Log.Entries), tt.wantEntries)
+			}
+		})
+	}
+}
+
+func TestContentBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		content Content
+		want    string
+		wantErr bool
+	}{
+		{name: "text", content: Content{Text: "hello"}, want: "hello"},
+		{name: "base64", content: Content{Text: "aGVsbG8=", Encoding: "base64"}, want: "hello"},
+		{name: "bad base64", content: Content{Text: "%%%", Encoding: "base64"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.content.Body()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Body() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Body() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNameValues(t *testing.T) {
+	got := NewNameValues(map[string][]string{"b": {"2", "3"}, "a": {"1"}})
+	want := []NameValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}, {Name: "b", Value: "3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewNameValues() = %v, want %v", got, want)
+	}
+
+	if got := NewNameValues(nil); got == nil || len(got) != 0 {
+		t.Errorf("NewNameValues(nil) = %#v, want empty slice", got)
+	}
+}
@@ -0,0 +1,144 @@
+package jsontool
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Parse parses json string into generic value
+func Parse(s string) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal([]byte(s), &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Valid checks if string is valid json
+func Valid(s string) bool {
+	return json.Valid([]byte(s))
+}
+
+// Contains checks if expected json value is a subset of actual:
+// objects may have extra keys, arrays may have extra elements in any order
+func Contains(actual, expected interface{}) bool {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for k, ev := range exp {
+			av, ok := act[k]
+			if !ok || !Contains(av, ev) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return false
+		}
+
+		for _, ev := range exp {
+			found := false
+			for _, av := range act {
+				if Contains(av, ev) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return reflect.DeepEqual(actual, expected)
+	}
+}
+
+// Diff finds first json path where actual differs from expected,
+// in contains mode actual may have extra keys & elements
+func Diff(actual, expected interface{}, contains bool) (string, bool) {
+	return diff("$", actual, expected, contains)
+}
+
+func diff(path string, actual, expected interface{}, contains bool) (string, bool) {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return path, true
+		}
+
+		keys := make([]string, 0, len(exp))
+		for k := range exp {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			av, ok := act[k]
+			if !ok {
+				return path + "." + k, true
+			}
+
+			if p, ok := diff(path+"."+k, av, exp[k], contains); ok {
+				return p, true
+			}
+		}
+
+		if !contains {
+			for k := range act {
+				if _, ok := exp[k]; !ok {
+					return path + "." + k, true
+				}
+			}
+		}
+
+		return "", false
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return path, true
+		}
+
+		if contains {
+			for i, ev := range exp {
+				if !Contains(act, []interface{}{ev}) {
+					return fmt.Sprintf("%s[%d]", path, i), true
+				}
+			}
+
+			return "", false
+		}
+
+		for i := 0; i < len(exp) && i < len(act); i++ {
+			if p, ok := diff(fmt.Sprintf("%s[%d]", path, i), act[i], exp[i], contains); ok {
+				return p, true
+			}
+		}
+
+		if len(exp) != len(act) {
+			return path, true
+		}
+
+		return "", false
+	default:
+		if !reflect.DeepEqual(actual, expected) {
+			return path, true
+		}
+
+		return "", false
+	}
+}
@@ -0,0 +1,80 @@
+package jsontool
+
+import (
+	"testing"
+)
+
+func mustParse(t *testing.T, s string) interface{} {
+	t.Helper()
+
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", s, err)
+	}
+
+	return v
+}
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   string
+		expected string
+		want     bool
+	}{
+		{name: "equal scalars", actual: `1`, expected: `1`, want: true},
+		{name: "different scalars", actual: `1`, expected: `2`},
+		{name: "number vs string", actual: `1`, expected: `"1"`},
+		{name: "extra keys", actual: `{"a":1,"b":2}`, expected: `{"a":1}`, want: true},
+		{name: "missing key", actual: `{"b":2}`, expected: `{"a":1}`},
+		{name: "nested object", actual: `{"a":{"b":{"c":1,"d":2}}}`, expected: `{"a":{"b":{"c":1}}}`, want: true},
+		{name: "nested value differs", actual: `{"a":{"b":{"c":1}}}`, expected: `{"a":{"b":{"c":2}}}`},
+		{name: "array subset any order", actual: `[1,2,3]`, expected: `[3,1]`, want: true},
+		{name: "array element missing", actual: `[1,2]`, expected: `[4]`},
+		{name: "array of objects", actual: `[{"id":1,"x":1},{"id":2}]`, expected: `[{"id":2}]`, want: true},
+		{name: "object vs array", actual: `{"a":1}`, expected: `[1]`},
+		{name: "array vs object", actual: `[1]`, expected: `{"a":1}`},
+		{name: "null", actual: `{"a":null}`, expected: `{"a":null}`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Contains(mustParse(t, tt.actual), mustParse(t, tt.expected)); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   string
+		expected string
+		contains bool
+		wantPath string
+	}{
+		{name: "equal", actual: `{"a":[1,{"b":2}]}`, expected: `{"a":[1,{"b":2}]}`},
+		{name: "key order", actual: `{"b":1,"a":2}`, expected: `{"a":2,"b":1}`},
+		{name: "scalar differs", actual: `{"a":1}`, expected: `{"a":2}`, wantPath: "$.a"},
+		{name: "missing key", actual: `{"a":1}`, expected: `{"a":1,"b":2}`, wantPath: "$.b"},
+		{name: "extra key", actual: `{"a":1,"b":2}`, expected: `{"a":1}`, wantPath: "$.b"},
+		{name: "extra key in contains mode", actual: `{"a":1,"b":2}`, expected: `{"a":1}`, contains: true},
+		{name: "nested", actual: `{"a":{"b":[1,2]}}`, expected: `{"a":{"b":[1,3]}}`, wantPath: "$.a.b[1]"},
+		{name: "array length", actual: `[1,2]`, expected: `[1]`, wantPath: "$"},
+		{name: "array order", actual: `[2,1]`, expected: `[1,2]`, wantPath: "$[0]"},
+		{name: "array order in contains mode", actual: `[2,1,3]`, expected: `[1,2]`, contains: true},
+		{name: "array element missing in contains mode", actual: `[1]`, expected: `[1,2]`, contains: true, wantPath: "$[1]"},
+		{name: "type mismatch", actual: `{"a":"1"}`, expected: `{"a":1}`, wantPath: "$.a"},
+		{name: "object vs array", actual: `{"a":[]}`, expected: `{"a":{}}`, wantPath: "$.a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, differs := Diff(mustParse(t, tt.actual), mustParse(t, tt.expected), tt.contains)
+			if differs != (tt.wantPath != "") || path != tt.wantPath {
+				t.Errorf("Diff() = %q, %v, want %q", path, differs, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package jsontool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const lengthFunc = "length()"
+
+// Path is a parsed JSONPath subset: $.a.b, $['a'], $.items[0], $.items.length()
+type Path struct {
+	raw    string
+	steps  []step
+	length bool
+}
+
+type step struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// ParsePath parses JSONPath expression
+func ParsePath(s string) (Path, error) {
+	if !strings.HasPrefix(s, "$") {
+		return Path{}, fmt.Errorf("path [%s] must start with $", s)
+	}
+
+	p := Path{raw: s}
+	rest := s[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+
+			key := rest[:end]
+			rest = rest[end:]
+			if key == "" {
+				return Path{}, fmt.Errorf("empty key in path [%s]", s)
+			}
+
+			if key == lengthFunc {
+				if rest != "" {
+					return Path{}, fmt.Errorf("%s must be last in path [%s]", lengthFunc, s)
+				}
+
+				p.length = true
+				continue
+			}
+
+			p.steps = append(p.steps, step{key: key})
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return Path{}, fmt.Errorf("unclosed bracket in path [%s]", s)
+			}
+
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				p.steps = append(p.steps, step{key: inner[1 : len(inner)-1]})
+				continue
+			}
+
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return Path{}, fmt.Errorf("index [%s] in path [%s] is not valid", inner, s)
+			}
+
+			p.steps = append(p.steps, step{index: idx, isIdx: true})
+		default:
+			return Path{}, fmt.Errorf("unexpected [%c] in path [%s]", rest[0], s)
+		}
+	}
+
+	return p, nil
+}
+
+// String returns raw path
+func (p Path) String() string {
+	return p.raw
+}
+
+// Get gets value by path, negative index counts from the end
+func (p Path) Get(doc interface{}) (interface{}, bool) {
+	cur := doc
+	for _, st := range p.steps {
+		if st.isIdx {
+			arr, ok := cur.([]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			idx := st.index
+			if idx < 0 {
+				idx += len(arr)
+			}
+
+			if idx < 0 || idx >= len(arr) {
+				return nil, false
+			}
+
+			cur = arr[idx]
+			continue
+		}
+
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		cur, ok = obj[st.key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	if !p.length {
+		return cur, true
+	}
+
+	switch v := cur.(type) {
+	case []interface{}:
+		return float64(len(v)), true
+	case map[string]interface{}:
+		return float64(len(v)), true
+	case string:
+		return float64(utf8.RuneCountInString(v)), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,75 @@
+package jsontool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "$"},
+		{path: "$.a.b"},
+		{path: "$['a b'].c"},
+		{path: `$["a"][0]`},
+		{path: "$.items[-1]"},
+		{path: "$.items.length()"},
+		{path: "a.b", wantErr: true},
+		{path: "$.", wantErr: true},
+		{path: "$..a", wantErr: true},
+		{path: "$.a[0", wantErr: true},
+		{path: "$.a[x]", wantErr: true},
+		{path: "$.a.length().b", wantErr: true},
+		{path: "$a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := ParsePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPathGet(t *testing.T) {
+	doc := mustParse(t, `{"a":{"b":[10,{"c":"x"}]},"s":"héllo","o":{"k":1,"l":2},"n":1,"a b":true}`)
+
+	tests := []struct {
+		path   string
+		want   interface{}
+		wantOK bool
+	}{
+		{path: "$", want: doc, wantOK: true},
+		{path: "$.a.b[0]", want: 10.0, wantOK: true},
+		{path: "$.a.b[1].c", want: "x", wantOK: true},
+		{path: "$.a.b[-1].c", want: "x", wantOK: true},
+		{path: "$['a b']", want: true, wantOK: true},
+		{path: "$.a.b.length()", want: 2.0, wantOK: true},
+		{path: "$.o.length()", want: 2.0, wantOK: true},
+		{path: "$.s.length()", want: 5.0, wantOK: true},
+		{path: "$.n.length()"},
+		{path: "$.a.b[2]"},
+		{path: "$.a.b[-3]"},
+		{path: "$.missing"},
+		{path: "$.a.b.c"},
+		{path: "$.a[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := ParsePath(tt.path)
+			if err != nil {
+				t.Fatalf("ParsePath() error = %v", err)
+			}
+
+			got, ok := p.Get(doc)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
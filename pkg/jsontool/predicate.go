@@ -0,0 +1,182 @@
+package jsontool
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// predicate operators
+const (
+	OpExists   = "exists"
+	OpEq       = "=="
+	OpNe       = "!="
+	OpGt       = ">"
+	OpGe       = ">="
+	OpLt       = "<"
+	OpLe       = "<="
+	OpMatches  = "matches"
+	OpContains = "contains"
+)
+
+var validOps = map[string]struct{}{
+	OpEq:       {},
+	OpNe:       {},
+	OpGt:       {},
+	OpGe:       {},
+	OpLt:       {},
+	OpLe:       {},
+	OpMatches:  {},
+	OpContains: {},
+}
+
+// Predicate is a parsed body predicate like `$.user.type == "premium"` or `$.id matches "^A-"`,
+// bare path checks that value exists
+type Predicate struct {
+	raw   string
+	path  Path
+	op    string
+	value interface{}
+	re    *regexp.Regexp
+}
+
+// ParsePredicate parses predicate expression `<path> [<op> <json value>]`
+func ParsePredicate(s string) (Predicate, error) {
+	s = strings.TrimSpace(s)
+	end := pathEnd(s)
+
+	path, err := ParsePath(s[:end])
+	if err != nil {
+		return Predicate{}, err
+	}
+
+	p := Predicate{raw: s, path: path, op: OpExists}
+	rest := strings.TrimSpace(s[end:])
+	if rest == "" {
+		return p, nil
+	}
+
+	opEnd := strings.IndexAny(rest, " \t")
+	if opEnd == -1 {
+		return Predicate{}, fmt.Errorf("value is missing in predicate [%s]", s)
+	}
+
+	p.op = rest[:opEnd]
+	if _, ok := validOps[p.op]; !ok {
+		return Predicate{}, fmt.Errorf("operator [%s] in predicate [%s] is not valid", p.op, s)
+	}
+
+	err = json.Unmarshal([]byte(strings.TrimSpace(rest[opEnd:])), &p.value)
+	if err != nil {
+		return Predicate{}, fmt.Errorf("value in predicate [%s] is not valid json: %s", s, err.Error())
+	}
+
+	if p.op == OpMatches {
+		pattern, ok := p.value.(string)
+		if !ok {
+			return Predicate{}, fmt.Errorf("regex in predicate [%s] must be a string", s)
+		}
+
+		p.re, err = regexp.Compile(pattern)
+		if err != nil {
+			return Predicate{}, fmt.Errorf("regex in predicate [%s] is not valid: %s", s, err.Error())
+		}
+	}
+
+	return p, nil
+}
+
+// pathEnd finds where path ends, whitespaces inside brackets belong to path
+func pathEnd(s string) int {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ' ', '\t':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(s)
+}
+
+// String returns raw predicate
+func (p Predicate) String() string {
+	return p.raw
+}
+
+// Eval evaluates predicate against parsed json document
+func (p Predicate) Eval(doc interface{}) bool {
+	actual, ok := p.path.Get(doc)
+	if !ok {
+		return false
+	}
+
+	switch p.op {
+	case OpExists:
+		return true
+	case OpEq:
+		return reflect.DeepEqual(actual, p.value)
+	case OpNe:
+		return !reflect.DeepEqual(actual, p.value)
+	case OpMatches:
+		str, ok := actual.(string)
+		return ok && p.re.MatchString(str)
+	case OpContains:
+		if str, ok := actual.(string); ok {
+			sub, ok := p.value.(string)
+			return ok && strings.Contains(str, sub)
+		}
+
+		return Contains(actual, p.value)
+	default:
+		return compare(actual, p.value, p.op)
+	}
+}
+
+func compare(actual, expected interface{}, op string) bool {
+	var cmp int
+	switch a := actual.(type) {
+	case float64:
+		e, ok := expected.(float64)
+		if !ok {
+			return false
+		}
+
+		switch {
+		case a < e:
+			cmp = -1
+		case a > e:
+			cmp = 1
+		}
+	case string:
+		e, ok := expected.(string)
+		if !ok {
+			return false
+		}
+
+		cmp = strings.Compare(a, e)
+	default:
+		return false
+	}
+
+	switch op {
+	case OpGt:
+		return cmp > 0
+	case OpGe:
+		return cmp >= 0
+	case OpLt:
+		return cmp < 0
+	case OpLe:
+		return cmp <= 0
+	}
+
+	return false
+}
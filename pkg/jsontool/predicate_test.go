@@ -0,0 +1,80 @@
+package jsontool
+
+import (
+	"testing"
+)
+
+func TestParsePredicate(t *testing.T) {
+	tests := []struct {
+		predicate string
+		wantErr   bool
+	}{
+		{predicate: "$.a"},
+		{predicate: `$.a == "x"`},
+		{predicate: "$['a b'] != null"},
+		{predicate: `$.id matches "^A-"`},
+		{predicate: `$.tags contains ["x"]`},
+		{predicate: "a == 1", wantErr: true},
+		{predicate: "$.a ==", wantErr: true},
+		{predicate: "$.a === 1", wantErr: true},
+		{predicate: "$.a == x", wantErr: true},
+		{predicate: "$.a matches 1", wantErr: true},
+		{predicate: `$.a matches "("`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.predicate, func(t *testing.T) {
+			_, err := ParsePredicate(tt.predicate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePredicate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPredicateEval(t *testing.T) {
+	doc := mustParse(t, `{"user":{"type":"premium","age":30,"id":"A-1"},"tags":["x","y"],"items":[{"id":1},{"id":2}],"note":null}`)
+
+	tests := []struct {
+		predicate string
+		want      bool
+	}{
+		{predicate: "$.user", want: true},
+		{predicate: "$.note", want: true},
+		{predicate: "$.missing"},
+		{predicate: `$.user.type == "premium"`, want: true},
+		{predicate: `$.user.type == "basic"`},
+		{predicate: `$.user.age == "30"`},
+		{predicate: `$.user.type != "basic"`, want: true},
+		{predicate: `$.missing != 1`},
+		{predicate: "$.user.age > 18", want: true},
+		{predicate: "$.user.age >= 30", want: true},
+		{predicate: "$.user.age < 30"},
+		{predicate: "$.user.age <= 30", want: true},
+		{predicate: `$.user.age > "18"`},
+		{predicate: `$.user.type > "basic"`, want: true},
+		{predicate: "$.user > 1"},
+		{predicate: `$.user.id matches "^A-[0-9]+$"`, want: true},
+		{predicate: `$.user.age matches "3"`},
+		{predicate: `$.user.type contains "prem"`, want: true},
+		{predicate: `$.user.type contains 1`},
+		{predicate: `$.tags contains ["y"]`, want: true},
+		{predicate: `$.items contains [{"id":2}]`, want: true},
+		{predicate: `$.items contains [{"id":3}]`},
+		{predicate: "$.items.length() == 2", want: true},
+		{predicate: "$.items[-1].id == 2", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.predicate, func(t *testing.T) {
+			p, err := ParsePredicate(tt.predicate)
+			if err != nil {
+				t.Fatalf("ParsePredicate() error = %v", err)
+			}
+
+			if got := p.Eval(doc); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
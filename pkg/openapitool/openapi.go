@@ -0,0 +1,310 @@
+package openapitool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mmiloslav/mock/pkg/yamltool"
+)
+
+// Document is an OpenAPI 3.x document, only fields needed for mocks are kept
+type Document struct {
+	OpenAPI string              `json:"openapi"`
+	Servers []Server            `json:"servers"`
+	Paths   map[string]PathItem `json:"paths"`
+
+	// basePath & routes are prepared by compile
+	basePath string
+	routes   []route
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type PathItem struct {
+	Parameters []Parameter `json:"parameters"`
+	Get        *Operation  `json:"get"`
+	Put        *Operation  `json:"put"`
+	Post       *Operation  `json:"post"`
+	Delete     *Operation  `json:"delete"`
+	Options    *Operation  `json:"options"`
+	Head       *Operation  `json:"head"`
+	Patch      *Operation  `json:"patch"`
+	Trace      *Operation  `json:"trace"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Parameters  []Parameter          `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Schema   *Schema     `json:"schema"`
+	Example  interface{} `json:"example"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Header struct {
+	Schema  *Schema     `json:"schema"`
+	Example interface{} `json:"example"`
+}
+
+type MediaType struct {
+	Schema   *Schema             `json:"schema"`
+	Example  interface{}         `json:"example"`
+	Examples map[string]*Example `json:"examples"`
+}
+
+type Example struct {
+	Summary string      `json:"summary"`
+	Value   interface{} `json:"value"`
+}
+
+// MethodOperation is an operation with its method & path
+type MethodOperation struct {
+	Method    string
+	Path      string
+	Operation *Operation
+	// Parameters are path item & operation parameters, operation ones override path item ones
+	Parameters []Parameter
+}
+
+// Load parses json or yaml OpenAPI 3.x document, local $ref are resolved and schemas are compiled
+func Load(data []byte) (Document, error) {
+	var raw interface{}
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(trimmed, &raw)
+	} else {
+		raw, err = yamltool.Parse(string(data))
+	}
+	if err != nil {
+		return Document{}, err
+	}
+
+	root, ok := raw.(map[string]interface{})
+	if !ok {
+		return Document{}, errors.New("openapi document must be an object")
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return Document{}, fmt.Errorf("openapi version [%s] is not supported, only 3.x is", version)
+	}
+
+	resolved := (&resolver{root: root, resolving: map[string]bool{}}).resolve(root)
+
+	b, err := json.Marshal(resolved)
+	if err != nil {
+		return Document{}, err
+	}
+
+	var doc Document
+	err = json.Unmarshal(b, &doc)
+	if err != nil {
+		return Document{}, err
+	}
+
+	err = doc.compile()
+	if err != nil {
+		return Document{}, err
+	}
+
+	return doc, nil
+}
+
+// BasePath gets path of first server url, it prefixes all operation paths
+func (d Document) BasePath() string {
+	if len(d.Servers) == 0 {
+		return ""
+	}
+
+	u, err := url.Parse(d.Servers[0].URL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// Operations gets all operations sorted by path & method
+func (d Document) Operations() []MethodOperation {
+	paths := make([]string, 0, len(d.Paths))
+	for p := range d.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var result []MethodOperation
+	for _, p := range paths {
+		item := d.Paths[p]
+		ops := []struct {
+			method string
+			op     *Operation
+		}{
+			{http.MethodGet, item.Get},
+			{http.MethodPut, item.Put},
+			{http.MethodPost, item.Post},
+			{http.MethodDelete, item.Delete},
+			{http.MethodOptions, item.Options},
+			{http.MethodHead, item.Head},
+			{http.MethodPatch, item.Patch},
+			{http.MethodTrace, item.Trace},
+		}
+
+		for _, o := range ops {
+			if o.op == nil {
+				continue
+			}
+
+			result = append(result, MethodOperation{
+				Method:     o.method,
+				Path:       p,
+				Operation:  o.op,
+				Parameters: mergeParameters(item.Parameters, o.op.Parameters),
+			})
+		}
+	}
+
+	return result
+}
+
+// mergeParameters joins path item & operation parameters, operation ones win by name & location
+func mergeParameters(pathParams, opParams []Parameter) []Parameter {
+	result := make([]Parameter, 0, len(pathParams)+len(opParams))
+	for _, p := range pathParams {
+		overridden := false
+		for _, o := range opParams {
+			if o.Name == p.Name && o.In == p.In {
+				overridden = true
+				break
+			}
+		}
+
+		if !overridden {
+			result = append(result, p)
+		}
+	}
+
+	return append(result, opParams...)
+}
+
+// JSONMediaType checks if media type carries json
+func JSONMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// PickMediaType gets preferred media type of content, json first
+func PickMediaType(content map[string]MediaType) (string, bool) {
+	if len(content) == 0 {
+		return "", false
+	}
+
+	keys := make([]string, 0, len(content))
+	for k := range content {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if JSONMediaType(k) {
+			return k, true
+		}
+	}
+
+	return keys[0], true
+}
+
+// resolver replaces local $ref objects with referenced nodes, cyclic refs are left as is
+type resolver struct {
+	root      map[string]interface{}
+	resolving map[string]bool
+}
+
+func (r *resolver) resolve(node interface{}) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#/") {
+			if r.resolving[ref] {
+				return v
+			}
+
+			target, ok := r.lookup(ref)
+			if !ok {
+				return v
+			}
+
+			r.resolving[ref] = true
+			resolved := r.resolve(target)
+			delete(r.resolving, ref)
+
+			return resolved
+		}
+
+		result := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			result[k] = r.resolve(child)
+		}
+
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, child := range v {
+			result[i] = r.resolve(child)
+		}
+
+		return result
+	default:
+		return v
+	}
+}
+
+// lookup gets node by json pointer ref like #/components/schemas/Pet
+func (r *resolver) lookup(ref string) (interface{}, bool) {
+	var node interface{} = r.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token, err := url.PathUnescape(token)
+		if err != nil {
+			return nil, false
+		}
+
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		node, ok = m[token]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return node, true
+}
@@ -0,0 +1,95 @@
+package openapitool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{name: "json", doc: `{"openapi":"3.0.3","paths":{}}`},
+		{name: "yaml", doc: "openapi: 3.1.0\npaths: {}\n"},
+		{name: "swagger 2", doc: `{"swagger":"2.0","paths":{}}`, wantErr: true},
+		{name: "not an object", doc: "- a\n- b\n", wantErr: true},
+		{name: "bad json", doc: `{"openapi":`, wantErr: true},
+		{
+			name: "cyclic ref",
+			doc: `{"openapi":"3.0.0","paths":{},"components":{"schemas":{"Node":{"type":"object",` +
+				`"properties":{"next":{"$ref":"#/components/schemas/Node"}}}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load([]byte(tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadResolvesRefs(t *testing.T) {
+	spec := `{"openapi":"3.0.0","paths":{"/a":{"get":{"parameters":[{"$ref":"#/components/parameters/Limit"}],"responses":{}}}},` +
+		`"components":{"parameters":{"Limit":{"name":"limit","in":"query","schema":{"type":"integer"}}}}}`
+
+	doc, err := Load([]byte(spec))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	params := doc.Paths["/a"].Get.Parameters
+	if len(params) != 1 || params[0].Name != "limit" || params[0].Schema == nil || !params[0].Schema.Type.Is("integer") {
+		t.Errorf("Load() parameters = %+v, want resolved limit parameter", params)
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []Server
+		want    string
+	}{
+		{name: "no servers", want: ""},
+		{name: "root", servers: []Server{{URL: "https://example.com/"}}, want: ""},
+		{name: "path", servers: []Server{{URL: "https://example.com/api/v1/"}}, want: "/api/v1"},
+		{name: "relative", servers: []Server{{URL: "/api"}, {URL: "/other"}}, want: "/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Document{Servers: tt.servers}).BasePath(); got != tt.want {
+				t.Errorf("BasePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperations(t *testing.T) {
+	doc := loadTestSpec(t)
+
+	var got []string
+	for _, mo := range doc.Operations() {
+		got = append(got, mo.Method+" "+mo.Path)
+	}
+
+	want := []string{"POST /users", "GET /users/me", "GET /users/{id}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Operations() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeParameters(t *testing.T) {
+	pathParams := []Parameter{{Name: "id", In: "path"}, {Name: "v", In: "query"}}
+	opParams := []Parameter{{Name: "v", In: "query", Required: true}, {Name: "v", In: "header"}}
+
+	got := mergeParameters(pathParams, opParams)
+	want := []Parameter{{Name: "id", In: "path"}, {Name: "v", In: "query", Required: true}, {Name: "v", In: "header"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeParameters() = %+v, want %+v", got, want)
+	}
+}
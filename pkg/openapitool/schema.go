@@ -0,0 +1,247 @@
+package openapitool
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxSampleDepth limits nesting of generated samples, cyclic schemas stop there
+	maxSampleDepth = 8
+	// maxSampleLength limits length of generated string samples
+	maxSampleLength = 1024
+)
+
+// Schema is an OpenAPI schema object, unresolved cyclic $ref is kept in Ref.
+// Validate requires schema compiled, as Load does
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 SchemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []interface{}      `json:"enum"`
+	Default              interface{}        `json:"default"`
+	Example              interface{}        `json:"example"`
+	Examples             []interface{}      `json:"examples"`
+	Nullable             bool               `json:"nullable"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+	Items                *Schema            `json:"items"`
+	AllOf                []*Schema          `json:"allOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+
+	// patternRe & additional are parsed by compile
+	patternRe  *regexp.Regexp
+	additional additionalProperties
+}
+
+// SchemaType is a schema type, 3.1 documents may list several types
+type SchemaType []string
+
+func (t *SchemaType) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+
+	var many []string
+	err := json.Unmarshal(b, &many)
+	if err != nil {
+		return err
+	}
+
+	*t = many
+
+	return nil
+}
+
+// Is checks if schema type is one of types
+func (t SchemaType) Is(name string) bool {
+	for _, v := range t {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// main gets first type which is not null
+func (t SchemaType) main() string {
+	for _, v := range t {
+		if v != "null" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// Sample gets example value of media type: example, first named example, schema example or generated sample
+func (m MediaType) Sample() (interface{}, bool) {
+	if m.Example != nil {
+		return m.Example, true
+	}
+
+	if names := m.ExampleNames(); len(names) > 0 {
+		return m.Examples[names[0]].Value, true
+	}
+
+	if m.Schema == nil {
+		return nil, false
+	}
+
+	return m.Schema.Sample(), true
+}
+
+// ExampleNames gets sorted names of named examples
+func (m MediaType) ExampleNames() []string {
+	names := make([]string, 0, len(m.Examples))
+	for name, e := range m.Examples {
+		if e != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Sample generates example value from schema
+func (s *Schema) Sample() interface{} {
+	return s.sample(0)
+}
+
+func (s *Schema) sample(depth int) interface{} {
+	if s == nil || s.Ref != "" || depth > maxSampleDepth {
+		return nil
+	}
+
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		merged := map[string]interface{}{}
+		for _, sub := range s.AllOf {
+			if obj, ok := sub.sample(depth + 1).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+
+		for k, v := range s.objectSample(depth) {
+			merged[k] = v
+		}
+
+		return merged
+	case len(s.OneOf) > 0:
+		return s.OneOf[0].sample(depth + 1)
+	case len(s.AnyOf) > 0:
+		return s.AnyOf[0].sample(depth + 1)
+	}
+
+	switch s.Type.main() {
+	case "object":
+		return s.objectSample(depth)
+	case "array":
+		if s.Items == nil {
+			return []interface{}{}
+		}
+
+		return []interface{}{s.Items.sample(depth + 1)}
+	case "string":
+		return s.stringSample()
+	case "integer":
+		if s.Minimum != nil {
+			return int64(*s.Minimum)
+		}
+
+		return 0
+	case "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+
+		return 0.0
+	case "boolean":
+		return true
+	}
+
+	if len(s.Properties) > 0 {
+		return s.objectSample(depth)
+	}
+
+	return nil
+}
+
+func (s *Schema) objectSample(depth int) map[string]interface{} {
+	obj := map[string]interface{}{}
+	for name, prop := range s.Properties {
+		obj[name] = prop.sample(depth + 1)
+	}
+
+	return obj
+}
+
+func (s *Schema) stringSample() string {
+	switch s.Format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "00:00:00"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "ipv6":
+		return "::1"
+	case "byte":
+		return "c3RyaW5n"
+	}
+
+	sample := "string"
+	if minLength, ok := sampleLength(s.MinLength); ok && len(sample) < minLength {
+		sample += strings.Repeat("s", minLength-len(sample))
+	}
+
+	if maxLength, ok := sampleLength(s.MaxLength); ok && len(sample) > maxLength {
+		sample = sample[:maxLength]
+	}
+
+	return sample
+}
+
+// sampleLength gets string length limit clamped to maxSampleLength, negative limit is treated as absent
+func sampleLength(limit *int) (int, bool) {
+	if limit == nil || *limit < 0 {
+		return 0, false
+	}
+
+	return min(*limit, maxSampleLength), true
+}
@@ -0,0 +1,69 @@
+package openapitool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestSchemaStringSample(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema Schema
+		want   string
+	}{
+		{name: "default", schema: Schema{}, want: "string"},
+		{name: "format", schema: Schema{Format: "uuid"}, want: "00000000-0000-0000-0000-000000000000"},
+		{name: "min length", schema: Schema{MinLength: intPtr(8)}, want: "stringss"},
+		{name: "max length", schema: Schema{MaxLength: intPtr(3)}, want: "str"},
+		{name: "negative max length is ignored", schema: Schema{MaxLength: intPtr(-1)}, want: "string"},
+		{name: "negative min length is ignored", schema: Schema{MinLength: intPtr(-5)}, want: "string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schema.stringSample(); got != tt.want {
+				t.Errorf("stringSample() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaStringSampleHugeMinLength(t *testing.T) {
+	s := Schema{MinLength: intPtr(1 << 30)}
+	if got := len(s.stringSample()); got != maxSampleLength {
+		t.Errorf("len(stringSample()) = %d, want %d", got, maxSampleLength)
+	}
+}
+
+func TestSchemaSample(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema Schema
+		want   interface{}
+	}{
+		{name: "example wins", schema: Schema{Type: SchemaType{"string"}, Example: "x"}, want: "x"},
+		{name: "enum", schema: Schema{Enum: []interface{}{"a", "b"}}, want: "a"},
+		{name: "integer minimum", schema: Schema{Type: SchemaType{"integer"}, Minimum: func() *float64 { v := 5.0; return &v }()}, want: int64(5)},
+		{
+			name: "object",
+			schema: Schema{Type: SchemaType{"object"}, Properties: map[string]*Schema{
+				"ok":   {Type: SchemaType{"boolean"}},
+				"tags": {Type: SchemaType{"array"}, Items: &Schema{Type: SchemaType{"string"}}},
+			}},
+			want: map[string]interface{}{"ok": true, "tags": []interface{}{"string"}},
+		},
+		{name: "unresolved ref", schema: Schema{Ref: "#/components/schemas/Node"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schema.Sample(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sample() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,505 @@
+package openapitool
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mmiloslav/mock/pkg/pathtool"
+)
+
+// route is an operation with its parsed path template
+type route struct {
+	op       MethodOperation
+	template pathtool.Template
+}
+
+// compile parses operation paths & schemas of document once, so request validation does not;
+// paths which are not valid templates are never matched
+func (d *Document) compile() error {
+	d.basePath = d.BasePath()
+	d.routes = nil
+	for _, mo := range d.Operations() {
+		for _, p := range mo.Parameters {
+			if err := p.Schema.compile(); err != nil {
+				return fmt.Errorf("%s %s param [%s]: %w", mo.Method, mo.Path, p.Name, err)
+			}
+		}
+
+		if rb := mo.Operation.RequestBody; rb != nil {
+			for mediaType, mt := range rb.Content {
+				if err := mt.Schema.compile(); err != nil {
+					return fmt.Errorf("%s %s request body [%s]: %w", mo.Method, mo.Path, mediaType, err)
+				}
+			}
+		}
+
+		for code, rs := range mo.Operation.Responses {
+			if rs == nil {
+				continue
+			}
+
+			for mediaType, mt := range rs.Content {
+				if err := mt.Schema.compile(); err != nil {
+					return fmt.Errorf("%s %s response [%s] [%s]: %w", mo.Method, mo.Path, code, mediaType, err)
+				}
+			}
+
+			for name, h := range rs.Headers {
+				if err := h.Schema.compile(); err != nil {
+					return fmt.Errorf("%s %s response [%s] header [%s]: %w", mo.Method, mo.Path, code, name, err)
+				}
+			}
+		}
+
+		t, err := pathtool.Parse(mo.Path)
+		if err != nil {
+			continue
+		}
+
+		d.routes = append(d.routes, route{op: mo, template: t})
+	}
+
+	return nil
+}
+
+// FindOperation gets operation of request method & path with captured path params,
+// paths without params win over templated ones. Document must be loaded with Load
+func (d Document) FindOperation(method, path string) (MethodOperation, map[string]string, bool) {
+	if !strings.HasPrefix(path, d.basePath) {
+		return MethodOperation{}, nil, false
+	}
+
+	path = strings.TrimPrefix(path, d.basePath)
+	if path == "" {
+		path = "/"
+	}
+
+	var found *MethodOperation
+	var foundParams map[string]string
+	for i := range d.routes {
+		rt := &d.routes[i]
+		if rt.op.Method != method {
+			continue
+		}
+
+		params, ok := rt.template.Match(path)
+		if !ok {
+			continue
+		}
+
+		if found == nil || len(params) < len(foundParams) {
+			found, foundParams = &rt.op, params
+		}
+	}
+
+	if found == nil {
+		return MethodOperation{}, nil, false
+	}
+
+	return *found, foundParams, true
+}
+
+// ValidateRequest checks request params & body against operation, returns violations
+func (mo MethodOperation) ValidateRequest(pathParams map[string]string, query url.Values, headers http.Header, body string) []string {
+	var violations []string
+	for _, p := range mo.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathParams[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = headers.Values(p.Name)
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if p.Required || p.In == "path" {
+				violations = append(violations, fmt.Sprintf("%s param [%s] is required", p.In, p.Name))
+			}
+
+			continue
+		}
+
+		violations = append(violations, p.Schema.validateParam(fmt.Sprintf("%s param [%s]", p.In, p.Name), values)...)
+	}
+
+	rb := mo.Operation.RequestBody
+	if rb == nil {
+		return violations
+	}
+
+	if body == "" {
+		if rb.Required {
+			violations = append(violations, "request body is required")
+		}
+
+		return violations
+	}
+
+	return append(violations, validateContent("request body", rb.Content, headers.Get("Content-Type"), body)...)
+}
+
+// ValidateResponse checks response status & body against operation responses, returns violations
+func (mo MethodOperation) ValidateResponse(status int, headers http.Header, body string) []string {
+	rs, ok := mo.Operation.response(status)
+	if !ok {
+		return []string{fmt.Sprintf("response status [%d] is not declared", status)}
+	}
+
+	if len(rs.Content) == 0 || body == "" {
+		return nil
+	}
+
+	return validateContent("response body", rs.Content, headers.Get("Content-Type"), body)
+}
+
+// response gets response of status, exact code wins over range like 2XX, then default
+func (op *Operation) response(status int) (*Response, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if rs, ok := op.Responses[key]; ok && rs != nil {
+			return rs, true
+		}
+	}
+
+	return nil, false
+}
+
+// validateContent checks body media type is declared and json body matches its schema
+func validateContent(name string, content map[string]MediaType, contentType, body string) []string {
+	if len(content) == 0 {
+		return nil
+	}
+
+	mt, ok := findMediaType(content, contentType)
+	if !ok {
+		return []string{fmt.Sprintf("%s content type [%s] is not declared", name, contentType)}
+	}
+
+	if mt.Schema == nil || (!JSONMediaType(contentType) && contentType != "") {
+		return nil
+	}
+
+	var value interface{}
+	err := json.Unmarshal([]byte(body), &value)
+	if err != nil {
+		return []string{fmt.Sprintf("%s is not valid json: %s", name, err.Error())}
+	}
+
+	return mt.Schema.Validate(name, value)
+}
+
+// findMediaType gets media type declared for content type, wildcards like application/* match too,
+// json one is used when content type is not set
+func findMediaType(content map[string]MediaType, contentType string) (MediaType, bool) {
+	if contentType == "" {
+		key, ok := PickMediaType(content)
+		return content[key], ok
+	}
+
+	base := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	candidates := []string{base, strings.Split(base, "/")[0] + "/*", "*/*"}
+	for _, c := range candidates {
+		for k, mt := range content {
+			if strings.ToLower(strings.TrimSpace(strings.Split(k, ";")[0])) == c {
+				return mt, true
+			}
+		}
+	}
+
+	return MediaType{}, false
+}
+
+// validateParam converts param string values according to schema type and validates them
+func (s *Schema) validateParam(name string, values []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	if s.Type.main() == "array" {
+		var items []string
+		for _, v := range values {
+			items = append(items, strings.Split(v, ",")...)
+		}
+
+		arr := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			arr = append(arr, s.Items.paramValue(item))
+		}
+
+		return s.Validate(name, arr)
+	}
+
+	return s.Validate(name, s.paramValue(values[0]))
+}
+
+// paramValue converts param string to value of schema type, unconvertible strings are kept
+func (s *Schema) paramValue(v string) interface{} {
+	if s == nil {
+		return v
+	}
+
+	switch s.Type.main() {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+
+	return v
+}
+
+// Validate checks value decoded from json against schema, violations are prefixed with value path
+func (s *Schema) Validate(path string, value interface{}) []string {
+	if s == nil || s.Ref != "" {
+		return nil
+	}
+
+	var violations []string
+	for _, sub := range s.AllOf {
+		violations = append(violations, sub.Validate(path, value)...)
+	}
+
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for _, sub := range s.OneOf {
+			if len(sub.Validate(path, value)) == 0 {
+				matched++
+			}
+		}
+
+		if matched != 1 {
+			violations = append(violations, fmt.Sprintf("%s: must match exactly one of oneOf schemas, matches %d", path, matched))
+		}
+	}
+
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, sub := range s.AnyOf {
+			if len(sub.Validate(path, value)) == 0 {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			violations = append(violations, fmt.Sprintf("%s: must match any of anyOf schemas", path))
+		}
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type.Is("null") || len(s.Type) == 0 {
+			return violations
+		}
+
+		return append(violations, fmt.Sprintf("%s: must not be null", path))
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		violations = append(violations, fmt.Sprintf("%s: must be one of %v", path, s.Enum))
+	}
+
+	if len(s.Type) > 0 && !s.typeMatches(value) {
+		return append(violations, fmt.Sprintf("%s: must be %s", path, strings.Join(s.Type, " or ")))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		violations = append(violations, s.validateObject(path, v)...)
+	case []interface{}:
+		violations = append(violations, s.validateArray(path, v)...)
+	case string:
+		violations = append(violations, s.validateString(path, v)...)
+	case float64:
+		violations = append(violations, s.validateNumber(path, v)...)
+	}
+
+	return violations
+}
+
+func (s *Schema) typeMatches(value interface{}) bool {
+	for _, t := range s.Type {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (s *Schema) validateObject(path string, obj map[string]interface{}) []string {
+	var violations []string
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			violations = append(violations, fmt.Sprintf("%s.%s: is required", path, name))
+		}
+	}
+
+	additional := s.additional
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		switch {
+		case ok:
+			violations = append(violations, prop.Validate(path+"."+name, obj[name])...)
+		case additional.forbidden:
+			violations = append(violations, fmt.Sprintf("%s.%s: is not allowed", path, name))
+		case additional.schema != nil:
+			violations = append(violations, additional.schema.Validate(path+"."+name, obj[name])...)
+		}
+	}
+
+	return violations
+}
+
+// additionalProperties is a parsed additionalProperties keyword, bool or schema
+type additionalProperties struct {
+	forbidden bool
+	schema    *Schema
+}
+
+// compile parses pattern & additionalProperties of schema and its subschemas
+func (s *Schema) compile() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern [%s] is not valid: %w", s.Pattern, err)
+		}
+
+		s.patternRe = re
+	}
+
+	if len(s.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(s.AdditionalProperties, &allowed); err == nil {
+			s.additional = additionalProperties{forbidden: !allowed}
+		} else {
+			var schema Schema
+			err = json.Unmarshal(s.AdditionalProperties, &schema)
+			if err != nil {
+				return fmt.Errorf("additionalProperties is not valid: %w", err)
+			}
+
+			s.additional = additionalProperties{schema: &schema}
+		}
+	}
+
+	children := []*Schema{s.Items, s.additional.schema}
+	children = append(children, s.AllOf...)
+	children = append(children, s.OneOf...)
+	children = append(children, s.AnyOf...)
+	for _, prop := range s.Properties {
+		children = append(children, prop)
+	}
+
+	for _, child := range children {
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) validateArray(path string, arr []interface{}) []string {
+	var violations []string
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		violations = append(violations, fmt.Sprintf("%s: must have at least %d items", path, *s.MinItems))
+	}
+
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		violations = append(violations, fmt.Sprintf("%s: must have at most %d items", path, *s.MaxItems))
+	}
+
+	for i, item := range arr {
+		violations = append(violations, s.Items.Validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+	}
+
+	return violations
+}
+
+func (s *Schema) validateString(path, v string) []string {
+	var violations []string
+	length := len([]rune(v))
+	if s.MinLength != nil && length < *s.MinLength {
+		violations = append(violations, fmt.Sprintf("%s: must be at least %d characters", path, *s.MinLength))
+	}
+
+	if s.MaxLength != nil && length > *s.MaxLength {
+		violations = append(violations, fmt.Sprintf("%s: must be at most %d characters", path, *s.MaxLength))
+	}
+
+	if s.patternRe != nil && !s.patternRe.MatchString(v) {
+		violations = append(violations, fmt.Sprintf("%s: must match pattern [%s]", path, s.Pattern))
+	}
+
+	return violations
+}
+
+func (s *Schema) validateNumber(path string, v float64) []string {
+	var violations []string
+	if s.Minimum != nil && v < *s.Minimum {
+		violations = append(violations, fmt.Sprintf("%s: must be >= %v", path, *s.Minimum))
+	}
+
+	if s.Maximum != nil && v > *s.Maximum {
+		violations = append(violations, fmt.Sprintf("%s: must be <= %v", path, *s.Maximum))
+	}
+
+	return violations
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,252 @@
+package openapitool
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const testSpec = `openapi: 3.0.0
+servers:
+  - url: https://example.com/api
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema: {type: integer}
+        - name: q
+          in: query
+          required: true
+          schema: {type: string, pattern: '^[a-z]+$'}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                additionalProperties: false
+                properties:
+                  id: {type: integer}
+  /users/me:
+    get:
+      responses:
+        '2XX': {description: ok}
+  /users:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string}
+      responses:
+        default: {description: ok}
+`
+
+func loadTestSpec(t *testing.T) Document {
+	t.Helper()
+
+	doc, err := Load([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	return doc
+}
+
+func TestLoadRejectsInvalidPattern(t *testing.T) {
+	spec := `{"openapi":"3.0.0","paths":{"/a":{"get":{"parameters":[{"name":"q","in":"query","schema":{"type":"string","pattern":"(?=x)"}}],"responses":{}}}}}`
+	if _, err := Load([]byte(spec)); err == nil {
+		t.Error("Load() error = nil, want invalid pattern error")
+	}
+}
+
+func TestFindOperation(t *testing.T) {
+	doc := loadTestSpec(t)
+
+	tests := []struct {
+		method, path string
+		wantPath     string
+		wantParams   map[string]string
+		wantOK       bool
+	}{
+		{method: http.MethodGet, path: "/api/users/1", wantPath: "/users/{id}", wantParams: map[string]string{"id": "1"}, wantOK: true},
+		{method: http.MethodGet, path: "/api/users/me", wantPath: "/users/me", wantParams: map[string]string{}, wantOK: true},
+		{method: http.MethodPost, path: "/api/users", wantPath: "/users", wantParams: map[string]string{}, wantOK: true},
+		{method: http.MethodDelete, path: "/api/users/1"},
+		{method: http.MethodGet, path: "/users/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			mo, params, ok := doc.FindOperation(tt.method, tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("FindOperation() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if !ok {
+				return
+			}
+
+			if mo.Path != tt.wantPath || !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("FindOperation() = %s %v, want %s %v", mo.Path, params, tt.wantPath, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	doc := loadTestSpec(t)
+	json := http.Header{"Content-Type": {"application/json"}}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		query   url.Values
+		headers http.Header
+		body    string
+		want    []string
+	}{
+		{name: "valid", method: http.MethodGet, path: "/api/users/1", query: url.Values{"q": {"abc"}}},
+		{
+			name: "path & query params", method: http.MethodGet, path: "/api/users/x", query: url.Values{"q": {"ABC"}},
+			want: []string{"path param [id]: must be integer", "query param [q]: must match pattern [^[a-z]+$]"},
+		},
+		{name: "missing query param", method: http.MethodGet, path: "/api/users/1", want: []string{"query param [q] is required"}},
+		{name: "missing body", method: http.MethodPost, path: "/api/users", want: []string{"request body is required"}},
+		{name: "body schema", method: http.MethodPost, path: "/api/users", headers: json, body: `{"name":1}`, want: []string{"request body.name: must be string"}},
+		{name: "bad json", method: http.MethodPost, path: "/api/users", headers: json, body: `{`, want: []string{"request body is not valid json: unexpected end of JSON input"}},
+		{
+			name: "content type", method: http.MethodPost, path: "/api/users", headers: http.Header{"Content-Type": {"text/plain"}}, body: "x",
+			want: []string{"request body content type [text/plain] is not declared"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mo, params, ok := doc.FindOperation(tt.method, tt.path)
+			if !ok {
+				t.Fatalf("FindOperation() ok = false")
+			}
+
+			headers := tt.headers
+			if headers == nil {
+				headers = http.Header{}
+			}
+
+			if got := mo.ValidateRequest(params, tt.query, headers, tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	doc := loadTestSpec(t)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+		want   []string
+	}{
+		{name: "valid", path: "/api/users/1", status: 200, body: `{"id":1}`},
+		{name: "undeclared status", path: "/api/users/1", status: 404, want: []string{"response status [404] is not declared"}},
+		{name: "status range", path: "/api/users/me", status: 204},
+		{
+			name: "body schema", path: "/api/users/1", status: 200, body: `{"x":1}`,
+			want: []string{"response body.id: is required", "response body.x: is not allowed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mo, _, ok := doc.FindOperation(http.MethodGet, tt.path)
+			if !ok {
+				t.Fatalf("FindOperation() ok = false")
+			}
+
+			if got := mo.ValidateResponse(tt.status, http.Header{}, tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestSchemaValidate(t *testing.T) {
+	str := &Schema{Type: SchemaType{"string"}}
+	num := &Schema{Type: SchemaType{"number"}}
+
+	tests := []struct {
+		name   string
+		schema Schema
+		value  interface{}
+		want   []string
+	}{
+		{name: "type", schema: Schema{Type: SchemaType{"string"}}, value: 1.0, want: []string{"v: must be string"}},
+		{name: "integer", schema: Schema{Type: SchemaType{"integer"}}, value: 1.5, want: []string{"v: must be integer"}},
+		{name: "multiple types", schema: Schema{Type: SchemaType{"string", "null"}}, value: nil},
+		{name: "not nullable", schema: Schema{Type: SchemaType{"string"}}, value: nil, want: []string{"v: must not be null"}},
+		{name: "nullable", schema: Schema{Type: SchemaType{"string"}, Nullable: true}, value: nil},
+		{name: "enum", schema: Schema{Enum: []interface{}{"a", "b"}}, value: "c", want: []string{"v: must be one of [a b]"}},
+		{name: "minimum", schema: Schema{Minimum: floatPtr(1)}, value: 0.0, want: []string{"v: must be >= 1"}},
+		{name: "maximum", schema: Schema{Maximum: floatPtr(1)}, value: 2.0, want: []string{"v: must be <= 1"}},
+		{name: "min length", schema: Schema{MinLength: intPtr(2)}, value: "é", want: []string{"v: must be at least 2 characters"}},
+		{name: "max length", schema: Schema{MaxLength: intPtr(1)}, value: "ab", want: []string{"v: must be at most 1 characters"}},
+		{name: "pattern", schema: Schema{Pattern: `^\d+$`}, value: "a1", want: []string{`v: must match pattern [^\d+$]`}},
+		{name: "min items", schema: Schema{MinItems: intPtr(1)}, value: []interface{}{}, want: []string{"v: must have at least 1 items"}},
+		{name: "max items", schema: Schema{MaxItems: intPtr(1)}, value: []interface{}{1.0, 2.0}, want: []string{"v: must have at most 1 items"}},
+		{name: "items", schema: Schema{Items: str}, value: []interface{}{"a", 1.0}, want: []string{"v[1]: must be string"}},
+		{
+			name:   "required & properties",
+			schema: Schema{Required: []string{"a"}, Properties: map[string]*Schema{"b": str}},
+			value:  map[string]interface{}{"b": 1.0},
+			want:   []string{"v.a: is required", "v.b: must be string"},
+		},
+		{
+			name:   "additional properties forbidden",
+			schema: Schema{AdditionalProperties: []byte(`false`)},
+			value:  map[string]interface{}{"a": 1.0},
+			want:   []string{"v.a: is not allowed"},
+		},
+		{
+			name:   "additional properties schema",
+			schema: Schema{AdditionalProperties: []byte(`{"type":"string"}`)},
+			value:  map[string]interface{}{"a": 1.0},
+			want:   []string{"v.a: must be string"},
+		},
+		{name: "allOf", schema: Schema{AllOf: []*Schema{str, {MinLength: intPtr(3)}}}, value: "ab", want: []string{"v: must be at least 3 characters"}},
+		{name: "oneOf none", schema: Schema{OneOf: []*Schema{str, num}}, value: true, want: []string{"v: must match exactly one of oneOf schemas, matches 0"}},
+		{name: "oneOf", schema: Schema{OneOf: []*Schema{str, num}}, value: "a"},
+		{name: "anyOf", schema: Schema{AnyOf: []*Schema{str, num}}, value: true, want: []string{"v: must match any of anyOf schemas"}},
+		{name: "unresolved ref", schema: Schema{Ref: "#/components/schemas/Node"}, value: 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.compile()
+			if err != nil {
+				t.Fatalf("compile() error = %v", err)
+			}
+
+			if got := tt.schema.Validate("v", tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package pathtool
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wildcard is the key under which the rest of the path matched by a trailing "*" is captured
+const Wildcard = "*"
+
+// Template is a parsed path template like /users/{id}/orders/{orderId} or /static/*
+type Template struct {
+	raw      string
+	segments []segment
+	wildcard bool
+}
+
+type segment struct {
+	value string
+	param bool
+}
+
+// IsTemplate checks if path contains template params or wildcard
+func IsTemplate(path string) bool {
+	return strings.ContainsAny(path, "{}*")
+}
+
+// Parse parses path template
+func Parse(path string) (Template, error) {
+	if !strings.HasPrefix(path, "/") {
+		return Template{}, fmt.Errorf("path [%s] must start with /", path)
+	}
+
+	t := Template{raw: path}
+	names := map[string]struct{}{}
+
+	parts := strings.Split(path[1:], "/")
+	for i, part := range parts {
+		if part == Wildcard {
+			if i != len(parts)-1 {
+				return Template{}, fmt.Errorf("wildcard is allowed only as last segment of [%s]", path)
+			}
+
+			t.wildcard = true
+			break
+		}
+
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			name := part[1 : len(part)-1]
+			if name == "" || strings.ContainsAny(name, "{}*") {
+				return Template{}, fmt.Errorf("param name [%s] in [%s] is not valid", name, path)
+			}
+
+			if _, ok := names[name]; ok {
+				return Template{}, fmt.Errorf("param [%s] is duplicated in [%s]", name, path)
+			}
+			names[name] = struct{}{}
+
+			t.segments = append(t.segments, segment{value: name, param: true})
+			continue
+		}
+
+		if strings.ContainsAny(part, "{}*") {
+			return Template{}, fmt.Errorf("segment [%s] in [%s] is not valid", part, path)
+		}
+
+		t.segments = append(t.segments, segment{value: part})
+	}
+
+	return t, nil
+}
+
+// String returns raw template
+func (t Template) String() string {
+	return t.raw
+}
+
+// Prefix returns first path segment if it is not a param or wildcard
+func (t Template) Prefix() (string, bool) {
+	if len(t.segments) == 0 || t.segments[0].param {
+		return "", false
+	}
+
+	return t.segments[0].value, true
+}
+
+// FirstSegment returns first segment of path
+func FirstSegment(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.Index(path, "/"); i != -1 {
+		return path[:i]
+	}
+
+	return path
+}
+
+// Match matches path against template and returns captured params
+func (t Template) Match(path string) (map[string]string, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, false
+	}
+
+	parts := strings.Split(path[1:], "/")
+	if len(parts) < len(t.segments) || (!t.wildcard && len(parts) != len(t.segments)) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, s := range t.segments {
+		if !s.param {
+			if parts[i] != s.value {
+				return nil, false
+			}
+
+			continue
+		}
+
+		if parts[i] == "" {
+			return nil, false
+		}
+
+		params[s.value] = parts[i]
+	}
+
+	if t.wildcard {
+		params[Wildcard] = strings.Join(parts[len(t.segments):], "/")
+	}
+
+	return params, true
+}
@@ -0,0 +1,137 @@
+package pathtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "/"},
+		{path: "/users/{id}"},
+		{path: "/users/{id}/orders/{orderId}"},
+		{path: "/static/*"},
+		{path: "/a.b/(c)/[d]+"},
+		{path: "users/{id}", wantErr: true},
+		{path: "/users/{}", wantErr: true},
+		{path: "/users/{id}/{id}", wantErr: true},
+		{path: "/users/{id", wantErr: true},
+		{path: "/users/id}", wantErr: true},
+		{path: "/users/x{id}", wantErr: true},
+		{path: "/*/users", wantErr: true},
+		{path: "/users/a*", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := Parse(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		path       string
+		wantParams map[string]string
+		wantOK     bool
+	}{
+		{name: "literal", template: "/users", path: "/users", wantParams: map[string]string{}, wantOK: true},
+		{name: "params", template: "/users/{id}/orders/{orderId}", path: "/users/1/orders/a-2", wantParams: map[string]string{"id": "1", "orderId": "a-2"}, wantOK: true},
+		{name: "literal differs", template: "/users/{id}", path: "/groups/1"},
+		{name: "trailing slash", template: "/users/{id}", path: "/users/1/"},
+		{name: "trailing slash in template", template: "/users/", path: "/users/", wantParams: map[string]string{}, wantOK: true},
+		{name: "trailing slash missing", template: "/users/", path: "/users"},
+		{name: "empty param segment", template: "/users/{id}/orders", path: "/users//orders"},
+		{name: "empty literal segment", template: "/a//b", path: "/a//b", wantParams: map[string]string{}, wantOK: true},
+		{name: "too short", template: "/users/{id}", path: "/users"},
+		{name: "too long", template: "/users/{id}", path: "/users/1/2"},
+		{name: "relative path", template: "/users/{id}", path: "users/1"},
+		{name: "regex chars are literal", template: "/a.b/{id}", path: "/a.b/1", wantParams: map[string]string{"id": "1"}, wantOK: true},
+		{name: "dot is not any char", template: "/a.b/{id}", path: "/axb/1"},
+		{name: "brackets are literal", template: "/[a]+/{id}", path: "/a/1"},
+		{name: "wildcard", template: "/static/*", path: "/static/css/a.css", wantParams: map[string]string{"*": "css/a.css"}, wantOK: true},
+		{name: "wildcard with params", template: "/u/{id}/*", path: "/u/1/x", wantParams: map[string]string{"id": "1", "*": "x"}, wantOK: true},
+		{name: "wildcard empty rest", template: "/static/*", path: "/static/", wantParams: map[string]string{"*": ""}, wantOK: true},
+		{name: "wildcard prefix differs", template: "/static/*", path: "/assets/a.css"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Parse(tt.template)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			params, ok := tmpl.Match(tt.path)
+			if ok != tt.wantOK || !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("Match() = %v, %v, want %v, %v", params, ok, tt.wantParams, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTemplatePrefix(t *testing.T) {
+	tests := []struct {
+		template   string
+		wantPrefix string
+		wantOK     bool
+	}{
+		{template: "/users/{id}", wantPrefix: "users", wantOK: true},
+		{template: "/{id}/users"},
+		{template: "/*"},
+		{template: "/", wantPrefix: "", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			tmpl, err := Parse(tt.template)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			prefix, ok := tmpl.Prefix()
+			if prefix != tt.wantPrefix || ok != tt.wantOK {
+				t.Errorf("Prefix() = %q, %v, want %q, %v", prefix, ok, tt.wantPrefix, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFirstSegment(t *testing.T) {
+	tests := map[string]string{
+		"/users/1": "users",
+		"/users":   "users",
+		"/":        "",
+		"users/1":  "users",
+		"//a":      "",
+	}
+
+	for path, want := range tests {
+		if got := FirstSegment(path); got != want {
+			t.Errorf("FirstSegment(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestIsTemplate(t *testing.T) {
+	tests := map[string]bool{
+		"/users":      false,
+		"/users/{id}": true,
+		"/static/*":   true,
+		"/a.b":        false,
+	}
+
+	for path, want := range tests {
+		if got := IsTemplate(path); got != want {
+			t.Errorf("IsTemplate(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package postmantool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SchemaVersion is a collection schema version supported on import
+const SchemaVersion = "v2.1"
+
+// variableRe matches {{name}} variables of collection
+var variableRe = regexp.MustCompile(`{{\s*([^{}]+?)\s*}}`)
+
+// Collection is a Postman v2.1 collection, only fields needed for mocks are kept
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Variable []Variable `json:"variable"`
+}
+
+type Info struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// Item is a folder when it has nested items, request otherwise
+type Item struct {
+	Name     string     `json:"name"`
+	Item     []Item     `json:"item"`
+	Request  *Request   `json:"request"`
+	Response []Response `json:"response"`
+}
+
+type Variable struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Disabled bool        `json:"disabled"`
+}
+
+// Request is a saved request, collections may keep it as bare url string
+type Request struct {
+	Method string    `json:"method"`
+	Header KeyValues `json:"header"`
+	URL    URL       `json:"url"`
+	Body   *Body     `json:"body"`
+}
+
+// URL is a request url, collections keep it either as raw string or as object
+type URL struct {
+	Raw      string     `json:"raw"`
+	Host     Host       `json:"host"`
+	Path     Path       `json:"path"`
+	Query    KeyValues  `json:"query"`
+	Variable []Variable `json:"variable"`
+}
+
+// Host is an url host joined from its parts, it may carry a base path in variable like {{baseUrl}}
+type Host string
+
+// Path is an url path, collections keep it either as string or as segments
+type Path []string
+
+type Body struct {
+	Mode       string    `json:"mode"`
+	Raw        string    `json:"raw"`
+	URLEncoded KeyValues `json:"urlencoded"`
+}
+
+type Response struct {
+	Name            string    `json:"name"`
+	OriginalRequest *Request  `json:"originalRequest"`
+	Code            int       `json:"code"`
+	Header          KeyValues `json:"header"`
+	Body            string    `json:"body"`
+}
+
+type KeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+// KeyValues are headers or query params, string forms are ignored
+type KeyValues []KeyValue
+
+// Folder is a folder of collection with requests directly in it, collection root is a folder too
+type Folder struct {
+	// Name is a slash separated path of folder names
+	Name     string
+	Requests []Item
+}
+
+func (r *Request) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err == nil {
+		*r = Request{Method: http.MethodGet, URL: URL{Raw: raw}}
+		return nil
+	}
+
+	type plain Request
+	var p plain
+	err := json.Unmarshal(b, &p)
+	if err != nil {
+		return err
+	}
+
+	*r = Request(p)
+
+	return nil
+}
+
+func (u *URL) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err == nil {
+		*u = URL{Raw: raw}
+		return nil
+	}
+
+	type plain URL
+	var p plain
+	err := json.Unmarshal(b, &p)
+	if err != nil {
+		return err
+	}
+
+	*u = URL(p)
+
+	return nil
+}
+
+func (p *Path) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err == nil {
+		*p = strings.Split(strings.TrimPrefix(raw, "/"), "/")
+		return nil
+	}
+
+	var segments []string
+	err := json.Unmarshal(b, &segments)
+	if err != nil {
+		return err
+	}
+
+	*p = segments
+
+	return nil
+}
+
+func (h *Host) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err == nil {
+		*h = Host(raw)
+		return nil
+	}
+
+	var parts []string
+	err := json.Unmarshal(b, &parts)
+	if err != nil {
+		return err
+	}
+
+	*h = Host(strings.Join(parts, "."))
+
+	return nil
+}
+
+func (kv *KeyValues) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err == nil {
+		*kv = nil
+		return nil
+	}
+
+	var pairs []KeyValue
+	err := json.Unmarshal(b, &pairs)
+	if err != nil {
+		return err
+	}
+
+	*kv = pairs
+
+	return nil
+}
+
+// Decode reads Postman collection and checks its schema version
+func Decode(r io.Reader) (Collection, error) {
+	var c Collection
+	err := json.NewDecoder(r).Decode(&c)
+	if err != nil {
+		return Collection{}, err
+	}
+
+	if !strings.Contains(c.Info.Schema, SchemaVersion) {
+		return Collection{}, fmt.Errorf("collection schema [%s] is not supported, only %s is", c.Info.Schema, SchemaVersion)
+	}
+
+	if c.Item == nil {
+		return Collection{}, errors.New("collection items are missing")
+	}
+
+	return c, nil
+}
+
+// Folders gets collection root & all nested folders which have requests, in collection order.
+// Root requests are in folder named after collection
+func (c Collection) Folders() []Folder {
+	var folders []Folder
+	var walk func(name string, items []Item, nested bool)
+	walk = func(name string, items []Item, nested bool) {
+		folder := Folder{Name: name}
+		for _, item := range items {
+			if item.Request != nil {
+				folder.Requests = append(folder.Requests, item)
+			}
+		}
+
+		if len(folder.Requests) > 0 {
+			folders = append(folders, folder)
+		}
+
+		for _, item := range items {
+			if item.Request != nil {
+				continue
+			}
+
+			subName := item.Name
+			if nested {
+				subName = name + " / " + item.Name
+			}
+
+			walk(subName, item.Item, true)
+		}
+	}
+
+	walk(c.Info.Name, c.Item, false)
+
+	return folders
+}
+
+// Variables gets enabled collection variables by key
+func (c Collection) Variables() map[string]string {
+	return newVariables(c.Variable)
+}
+
+func newVariables(vars []Variable) map[string]string {
+	result := make(map[string]string, len(vars))
+	for _, v := range vars {
+		if v.Disabled || v.Value == nil {
+			continue
+		}
+
+		result[v.Key] = fmt.Sprint(v.Value)
+	}
+
+	return result
+}
+
+// Resolve replaces known {{name}} variables in s, unknown ones are kept
+func Resolve(s string, vars map[string]string) string {
+	return variableRe.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := vars[variableRe.FindStringSubmatch(m)[1]]; ok {
+			return v
+		}
+
+		return m
+	})
+}
+
+// PathTemplate gets url path with resolved variables, base path of host is kept, path variables like :id
+// and unresolved {{name}} segments become template params like {id}
+func (u URL) PathTemplate(vars map[string]string) string {
+	pathVars := newVariables(u.Variable)
+
+	segments := rawPath(Resolve(u.Raw, vars))
+	if u.Path != nil {
+		segments = append(rawPath(Resolve(string(u.Host), vars)), u.Path...)
+	}
+
+	result := make([]string, 0, len(segments))
+	for _, s := range segments {
+		s = Resolve(s, vars)
+		switch {
+		case strings.HasPrefix(s, ":") && len(s) > 1:
+			if v, ok := pathVars[s[1:]]; ok && v != "" {
+				s = v
+			} else {
+				s = "{" + s[1:] + "}"
+			}
+		case variableRe.MatchString(s):
+			s = variableRe.ReplaceAllString(s, "{$1}")
+		}
+
+		result = append(result, s)
+	}
+
+	return "/" + strings.Join(result, "/")
+}
+
+// rawPath gets path segments of raw url, host may be an unresolved variable
+func rawPath(raw string) []string {
+	raw = strings.SplitN(strings.SplitN(raw, "#", 2)[0], "?", 2)[0]
+	if i := strings.Index(raw, "://"); i != -1 {
+		raw = raw[i+3:]
+	}
+
+	if !strings.HasPrefix(raw, "/") {
+		i := strings.Index(raw, "/")
+		if i == -1 {
+			return nil
+		}
+
+		raw = raw[i:]
+	}
+
+	raw = strings.TrimPrefix(raw, "/")
+	if raw == "" {
+		return nil
+	}
+
+	return strings.Split(raw, "/")
+}
+
+// QueryParams gets enabled url query params with resolved variables
+func (u URL) QueryParams(vars map[string]string) url.Values {
+	query := url.Values{}
+	if u.Query == nil {
+		parts := strings.SplitN(strings.SplitN(Resolve(u.Raw, vars), "#", 2)[0], "?", 2)
+		if len(parts) < 2 {
+			return query
+		}
+
+		parsed, err := url.ParseQuery(parts[1])
+		if err != nil {
+			return query
+		}
+
+		return parsed
+	}
+
+	for _, q := range u.Query {
+		if q.Disabled || q.Key == "" {
+			continue
+		}
+
+		query.Add(Resolve(q.Key, vars), Resolve(q.Value, vars))
+	}
+
+	return query
+}
+
+// Text gets request body text with resolved variables, only raw & urlencoded modes have one
+func (b *Body) Text(vars map[string]string) string {
+	if b == nil {
+		return ""
+	}
+
+	switch b.Mode {
+	case "raw":
+		return Resolve(b.Raw, vars)
+	case "urlencoded":
+		form := url.Values{}
+		for _, kv := range b.URLEncoded {
+			if !kv.Disabled {
+				form.Add(Resolve(kv.Key, vars), Resolve(kv.Value, vars))
+			}
+		}
+
+		return form.Encode()
+	}
+
+	return ""
+}
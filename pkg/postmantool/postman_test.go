@@ -0,0 +1,185 @@
+package postmantool
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const schemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{name: "v2.1", doc: `{"info":{"name":"c","schema":"` + schemaURL + `"},"item":[]}`},
+		{name: "v2.0", doc: `{"info":{"name":"c","schema":"https://schema.getpostman.com/json/collection/v2.0.0/collection.json"},"item":[]}`, wantErr: true},
+		{name: "missing items", doc: `{"info":{"name":"c","schema":"` + schemaURL + `"}}`, wantErr: true},
+		{name: "not json", doc: `item`, wantErr: true},
+		{
+			name: "string request & url forms",
+			doc: `{"info":{"name":"c","schema":"` + schemaURL + `"},"item":[` +
+				`{"name":"a","request":"http://x/a"},` +
+				`{"name":"b","request":{"method":"POST","url":{"raw":"http://x/b","host":["x"],"path":"b","query":"q=1"},"header":"Accept: */*"}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFolders(t *testing.T) {
+	rq := &Request{Method: "GET"}
+	c := Collection{
+		Info: Info{Name: "api"},
+		Item: []Item{
+			{Name: "root", Request: rq},
+			{Name: "users", Item: []Item{
+				{Name: "list", Request: rq},
+				{Name: "admin", Item: []Item{{Name: "get", Request: rq}}},
+			}},
+			{Name: "empty", Item: []Item{}},
+		},
+	}
+
+	var got []string
+	for _, f := range c.Folders() {
+		got = append(got, f.Name)
+	}
+
+	want := []string{"api", "users", "users / admin"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Folders() = %v, want %v", got, want)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	vars := map[string]string{"host": "x.com"}
+	if got := Resolve("http://{{ host }}/{{id}}", vars); got != "http://x.com/{{id}}" {
+		t.Errorf("Resolve() = %q", got)
+	}
+}
+
+func TestRawPath(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{raw: "http://localhost:8080", want: nil},
+		{raw: "http://localhost:8080/", want: nil},
+		{raw: "https://x.com/a/b?q=1#top", want: []string{"a", "b"}},
+		{raw: "{{baseUrl}}/users/:id", want: []string{"users", ":id"}},
+		{raw: "/a", want: []string{"a"}},
+		{raw: "x.com", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := rawPath(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rawPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathTemplate(t *testing.T) {
+	vars := map[string]string{"baseUrl": "https://api.x.com/v1", "version": "v2"}
+
+	tests := []struct {
+		name string
+		url  URL
+		want string
+	}{
+		{name: "raw", url: URL{Raw: "https://x.com/users"}, want: "/users"},
+		{name: "raw root", url: URL{Raw: "http://localhost:8080"}, want: "/"},
+		{name: "host base path", url: URL{Host: "{{baseUrl}}", Path: Path{"users", ":id"}}, want: "/v1/users/{id}"},
+		{name: "resolved variable", url: URL{Raw: "{{baseUrl}}/{{version}}/users"}, want: "/v1/v2/users"},
+		{name: "unresolved variable", url: URL{Host: "x.com", Path: Path{"users", "{{userId}}"}}, want: "/users/{userId}"},
+		{
+			name: "path variable value",
+			url:  URL{Host: "x.com", Path: Path{"users", ":id"}, Variable: []Variable{{Key: "id", Value: "42"}}},
+			want: "/users/42",
+		},
+		{
+			name: "empty path variable",
+			url:  URL{Host: "x.com", Path: Path{"users", ":id"}, Variable: []Variable{{Key: "id", Value: ""}}},
+			want: "/users/{id}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.url.PathTemplate(vars); got != tt.want {
+				t.Errorf("PathTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	vars := map[string]string{"limit": "10"}
+
+	tests := []struct {
+		name string
+		url  URL
+		want url.Values
+	}{
+		{name: "no query", url: URL{Raw: "http://x/a"}, want: url.Values{}},
+		{name: "raw", url: URL{Raw: "http://x/a?limit={{limit}}&q=a#top"}, want: url.Values{"limit": {"10"}, "q": {"a"}}},
+		{
+			name: "params",
+			url: URL{Raw: "http://x/a?ignored=1", Query: KeyValues{
+				{Key: "limit", Value: "{{limit}}"},
+				{Key: "off", Value: "1", Disabled: true},
+				{Key: "", Value: "x"},
+				{Key: "tag", Value: "a"},
+				{Key: "tag", Value: "b"},
+			}},
+			want: url.Values{"limit": {"10"}, "tag": {"a", "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.url.QueryParams(vars); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyText(t *testing.T) {
+	vars := map[string]string{"name": "bob"}
+
+	tests := []struct {
+		name string
+		body *Body
+		want string
+	}{
+		{name: "nil", body: nil, want: ""},
+		{name: "raw", body: &Body{Mode: "raw", Raw: `{"name":"{{name}}"}`}, want: `{"name":"bob"}`},
+		{
+			name: "urlencoded",
+			body: &Body{Mode: "urlencoded", URLEncoded: KeyValues{{Key: "name", Value: "{{name}}"}, {Key: "off", Value: "1", Disabled: true}}},
+			want: "name=bob",
+		},
+		{name: "formdata", body: &Body{Mode: "formdata"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.body.Text(vars); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
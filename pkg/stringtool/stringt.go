@@ -1,6 +1,7 @@
 package stringtool
 
 import (
+	"regexp"
 	"strings"
 )
 
@@ -8,3 +9,22 @@ import (
 func Empty(s string) bool {
 	return strings.TrimSpace(s) == ""
 }
+
+// CompileWildcard compiles pattern where * matches any sequence and ? matches any single char
+func CompileWildcard(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String())
+}
@@ -0,0 +1,156 @@
+package templatetool
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmiloslav/mock/pkg/jsontool"
+)
+
+// helper is a template function with string args
+type helper struct {
+	minArgs int
+	maxArgs int
+	fn      func(args []string) (string, error)
+}
+
+var helpers = map[string]helper{
+	// {{jsonPath request.body "$.name"}}
+	"jsonPath": {minArgs: 2, maxArgs: 2, fn: jsonPathHelper},
+	// {{uuid}}
+	"uuid": {minArgs: 0, maxArgs: 0, fn: func([]string) (string, error) { return uuid.New().String(), nil }},
+	// {{now}}, {{now "unix"}}, {{now "2006-01-02" "-1d"}}
+	"now": {minArgs: 0, maxArgs: 2, fn: nowHelper},
+	// {{dateAdd request.query.from "+2h" "2006-01-02T15:04:05Z07:00"}}
+	"dateAdd": {minArgs: 2, maxArgs: 3, fn: dateAddHelper},
+	// {{randomInt 1 100}}
+	"randomInt": {minArgs: 2, maxArgs: 2, fn: randomIntHelper},
+}
+
+func jsonPathHelper(args []string) (string, error) {
+	path, err := jsontool.ParsePath(args[1])
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := jsontool.Parse(args[0])
+	if err != nil {
+		return "", nil
+	}
+
+	v, ok := path.Get(doc)
+	if !ok {
+		return "", nil
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func nowHelper(args []string) (string, error) {
+	t := time.Now().UTC()
+	layout := ""
+	if len(args) > 0 {
+		layout = args[0]
+	}
+
+	if len(args) > 1 {
+		d, err := parseOffset(args[1])
+		if err != nil {
+			return "", err
+		}
+
+		t = t.Add(d)
+	}
+
+	return formatTime(t, layout), nil
+}
+
+func dateAddHelper(args []string) (string, error) {
+	layout := time.RFC3339
+	if len(args) > 2 {
+		layout = args[2]
+	}
+
+	t, err := time.Parse(layout, args[0])
+	if err != nil {
+		return "", fmt.Errorf("date [%s] does not match layout [%s]", args[0], layout)
+	}
+
+	d, err := parseOffset(args[1])
+	if err != nil {
+		return "", err
+	}
+
+	return formatTime(t.Add(d), layout), nil
+}
+
+func randomIntHelper(args []string) (string, error) {
+	lo, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", fmt.Errorf("randomInt min [%s] is not valid", args[0])
+	}
+
+	hi, err := strconv.Atoi(args[1])
+	if err != nil {
+		return "", fmt.Errorf("randomInt max [%s] is not valid", args[1])
+	}
+
+	if hi < lo {
+		return "", fmt.Errorf("randomInt max [%d] is less than min [%d]", hi, lo)
+	}
+
+	// range size hi-lo+1 must fit int for rand.IntN
+	if lo <= 0 && hi >= math.MaxInt+lo {
+		return "", fmt.Errorf("randomInt range [%d, %d] is too wide", lo, hi)
+	}
+
+	return strconv.Itoa(lo + rand.IntN(hi-lo+1)), nil
+}
+
+// parseOffset parses duration like +1h30m, -15m or days like +2d
+func parseOffset(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("offset [%s] is not valid", s)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("offset [%s] is not valid", s)
+	}
+
+	return d, nil
+}
+
+// formatTime formats time with go layout, unix & unix_ms, RFC3339 by default
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case "":
+		return t.Format(time.RFC3339)
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.Format(layout)
+	}
+}
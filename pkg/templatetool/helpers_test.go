@@ -0,0 +1,48 @@
+package templatetool
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRandomIntHelper(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "range", args: []string{"1", "10"}},
+		{name: "single value", args: []string{"5", "5"}},
+		{name: "negative range", args: []string{"-10", "-1"}},
+		{name: "max less than min", args: []string{"10", "1"}, wantErr: true},
+		{name: "min not valid", args: []string{"x", "1"}, wantErr: true},
+		{name: "max not valid", args: []string{"1", "x"}, wantErr: true},
+		{name: "full int range overflows", args: []string{"-9223372036854775808", "9223372036854775807"}, wantErr: true},
+		{name: "zero to max int overflows", args: []string{"0", "9223372036854775807"}, wantErr: true},
+		{name: "widest valid range", args: []string{"1", "9223372036854775807"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := randomIntHelper(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("randomIntHelper() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			n, err := strconv.Atoi(got)
+			if err != nil {
+				t.Fatalf("randomIntHelper() = %q is not int", got)
+			}
+
+			lo, _ := strconv.Atoi(tt.args[0])
+			hi, _ := strconv.Atoi(tt.args[1])
+			if n < lo || n > hi {
+				t.Errorf("randomIntHelper() = %d, want in [%d, %d]", n, lo, hi)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package templatetool
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const requestPrefix = "request."
+
+// Request is a request data available in templates
+type Request struct {
+	Method     string
+	URL        string
+	PathParams map[string]string
+	Query      url.Values
+	Headers    http.Header
+	Body       string
+}
+
+// validateVariable checks variable is one of
+// request.method, request.url, request.body, request.path.<name>, request.query.<name>, request.headers.<name>
+func validateVariable(name string) error {
+	field, key, _ := strings.Cut(strings.TrimPrefix(name, requestPrefix), ".")
+	if !strings.HasPrefix(name, requestPrefix) {
+		return fmt.Errorf("unknown variable [%s]", name)
+	}
+
+	switch field {
+	case "method", "url", "body":
+		if key != "" {
+			return fmt.Errorf("variable [%s] has no fields", name)
+		}
+	case "path", "query", "headers":
+		if key == "" {
+			return fmt.Errorf("variable [%s] requires a name", name)
+		}
+	default:
+		return fmt.Errorf("unknown variable [%s]", name)
+	}
+
+	return nil
+}
+
+// lookup gets variable value, missing values are empty
+func (rq Request) lookup(name string) string {
+	field, key, _ := strings.Cut(strings.TrimPrefix(name, requestPrefix), ".")
+	switch field {
+	case "method":
+		return rq.Method
+	case "url":
+		return rq.URL
+	case "body":
+		return rq.Body
+	case "path":
+		return rq.PathParams[key]
+	case "query":
+		return rq.Query.Get(key)
+	case "headers":
+		return rq.Headers.Get(key)
+	}
+
+	return ""
+}
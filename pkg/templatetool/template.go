@@ -0,0 +1,217 @@
+package templatetool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	openTag  = "{{"
+	closeTag = "}}"
+)
+
+// Template is a parsed response template with {{...}} expressions:
+// {{request.path.id}}, {{request.query.page}}, {{request.headers.X-Request-ID}},
+// {{jsonPath request.body "$.name"}}, {{uuid}}, {{now "2006-01-02" "+1d"}}, {{randomInt 1 10}}
+type Template struct {
+	raw   string
+	parts []part
+}
+
+// part is either literal text or expression
+type part struct {
+	text string
+	expr *expr
+}
+
+// expr is a variable reference or helper call with args
+type expr struct {
+	helper string
+	args   []arg
+}
+
+// arg is a string/number literal or variable reference
+type arg struct {
+	literal  string
+	variable string
+}
+
+// Parse parses template
+func Parse(s string) (*Template, error) {
+	t := &Template{raw: s}
+	rest := s
+	for rest != "" {
+		start := strings.Index(rest, openTag)
+		if start == -1 {
+			t.parts = append(t.parts, part{text: rest})
+			break
+		}
+
+		if start > 0 {
+			t.parts = append(t.parts, part{text: rest[:start]})
+		}
+
+		rest = rest[start+len(openTag):]
+		end := strings.Index(rest, closeTag)
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed %s in template", openTag)
+		}
+
+		e, err := parseExpr(strings.TrimSpace(rest[:end]))
+		if err != nil {
+			return nil, err
+		}
+
+		t.parts = append(t.parts, part{expr: e})
+		rest = rest[end+len(closeTag):]
+	}
+
+	return t, nil
+}
+
+// IsTemplate checks if string contains template expressions
+func IsTemplate(s string) bool {
+	return strings.Contains(s, openTag)
+}
+
+func parseExpr(s string) (*expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression in template")
+	}
+
+	head := tokens[0]
+	if head.variable != "" && len(tokens) == 1 {
+		if _, ok := helpers[head.variable]; !ok {
+			err := validateVariable(head.variable)
+			if err != nil {
+				return nil, err
+			}
+
+			return &expr{args: tokens}, nil
+		}
+	}
+
+	if head.variable == "" {
+		return nil, fmt.Errorf("expression [%s] must start with helper or variable", s)
+	}
+
+	h, ok := helpers[head.variable]
+	if !ok {
+		return nil, fmt.Errorf("unknown helper [%s]", head.variable)
+	}
+
+	args := tokens[1:]
+	if len(args) < h.minArgs || len(args) > h.maxArgs {
+		return nil, fmt.Errorf("helper [%s] expects %d to %d args, got %d", head.variable, h.minArgs, h.maxArgs, len(args))
+	}
+
+	for _, a := range args {
+		if a.variable != "" {
+			err := validateVariable(a.variable)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &expr{helper: head.variable, args: args}, nil
+}
+
+// tokenize splits expression into literals & identifiers
+func tokenize(s string) ([]arg, error) {
+	var tokens []arg
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return tokens, nil
+		}
+
+		if s[0] == '"' {
+			// backslash escapes next char, so "a\\" ends at its last quote
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+
+				end++
+			}
+
+			if end >= len(s) {
+				return nil, fmt.Errorf("unclosed string in [%s]", s)
+			}
+
+			literal, err := strconv.Unquote(s[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("string [%s] is not valid: %s", s[:end+1], err.Error())
+			}
+
+			tokens = append(tokens, arg{literal: literal})
+			s = s[end+1:]
+			continue
+		}
+
+		end := strings.IndexAny(s, " \t")
+		if end == -1 {
+			end = len(s)
+		}
+
+		word := s[:end]
+		s = s[end:]
+		if _, err := strconv.ParseFloat(word, 64); err == nil {
+			tokens = append(tokens, arg{literal: word})
+			continue
+		}
+
+		tokens = append(tokens, arg{variable: word})
+	}
+}
+
+// String returns raw template
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Execute renders template with request data
+func (t *Template) Execute(rq Request) (string, error) {
+	var sb strings.Builder
+	for _, p := range t.parts {
+		if p.expr == nil {
+			sb.WriteString(p.text)
+			continue
+		}
+
+		v, err := p.expr.eval(rq)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(v)
+	}
+
+	return sb.String(), nil
+}
+
+func (e *expr) eval(rq Request) (string, error) {
+	args := make([]string, 0, len(e.args))
+	for _, a := range e.args {
+		if a.variable != "" {
+			args = append(args, rq.lookup(a.variable))
+			continue
+		}
+
+		args = append(args, a.literal)
+	}
+
+	if e.helper == "" {
+		return args[0], nil
+	}
+
+	return helpers[e.helper].fn(args)
+}
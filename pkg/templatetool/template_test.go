@@ -0,0 +1,39 @@
+package templatetool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []arg
+		wantErr bool
+	}{
+		{name: "empty", s: "  ", want: nil},
+		{name: "variable & number", s: "randomInt 1 10", want: []arg{{variable: "randomInt"}, {literal: "1"}, {literal: "10"}}},
+		{name: "string", s: `upper "a b"`, want: []arg{{variable: "upper"}, {literal: "a b"}}},
+		{name: "escaped quote", s: `"a\"b" x`, want: []arg{{literal: `a"b`}, {variable: "x"}}},
+		{name: "escaped backslash before closing quote", s: `"a\\" x`, want: []arg{{literal: `a\`}, {variable: "x"}}},
+		{name: "escaped backslash & quote", s: `"a\\\"" x`, want: []arg{{literal: `a\"`}, {variable: "x"}}},
+		{name: "unclosed string", s: `"a`, wantErr: true},
+		{name: "unclosed after escaped quote", s: `"a\"`, wantErr: true},
+		{name: "trailing backslash", s: `"a\`, wantErr: true},
+		{name: "bad escape", s: `"\q"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenize(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tokenize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package yamltool
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parse parses yaml document into the same values encoding/json produces for interface{}:
+// map[string]interface{}, []interface{}, string, float64, bool & nil.
+// Mapping keys like response codes and timestamps are kept as strings
+func Parse(doc string) (interface{}, error) {
+	var root yaml.Node
+	err := yaml.Unmarshal([]byte(doc), &root)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.Kind == 0 {
+		return nil, nil
+	}
+
+	c := converter{budget: max(maxAliasRatio*countNodes(&root), minNodeBudget)}
+
+	return c.convert(&root)
+}
+
+// aliases may expand document to maxAliasRatio times its own nodes, small documents get minNodeBudget
+const (
+	maxAliasRatio = 10
+	minNodeBudget = 10000
+)
+
+// converter converts yaml nodes while budget of converted nodes lasts, so alias bombs are rejected
+type converter struct {
+	budget int
+}
+
+// countNodes counts nodes of document, aliases are not followed
+func countNodes(n *yaml.Node) int {
+	count := 1
+	for _, child := range n.Content {
+		count += countNodes(child)
+	}
+
+	return count
+}
+
+// convert converts yaml node to json compatible value
+func (c *converter) convert(n *yaml.Node) (interface{}, error) {
+	c.budget--
+	if c.budget < 0 {
+		return nil, fmt.Errorf("line %d: yaml document expands too much through aliases", n.Line)
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+
+		return c.convert(n.Content[0])
+	case yaml.AliasNode:
+		return c.convert(n.Alias)
+	case yaml.MappingNode:
+		result := make(map[string]interface{}, len(n.Content)/2)
+		var merges []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].ShortTag() == "!!merge" {
+				merges = append(merges, n.Content[i+1])
+				continue
+			}
+
+			value, err := c.convert(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			result[n.Content[i].Value] = value
+		}
+
+		for _, m := range merges {
+			err := c.merge(result, m)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	case yaml.SequenceNode:
+		result := make([]interface{}, 0, len(n.Content))
+		for _, child := range n.Content {
+			value, err := c.convert(child)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, value)
+		}
+
+		return result, nil
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!str", "!!timestamp", "!!binary":
+			return n.Value, nil
+		case "!!int", "!!float":
+			var f float64
+			err := n.Decode(&f)
+			return f, err
+		}
+
+		var value interface{}
+		err := n.Decode(&value)
+
+		return value, err
+	default:
+		return nil, fmt.Errorf("line %d: unsupported yaml node", n.Line)
+	}
+}
+
+// merge adds keys of << merge value to mapping, explicit keys and keys merged earlier win
+func (c *converter) merge(result map[string]interface{}, n *yaml.Node) error {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		value, err := c.convert(n)
+		if err != nil {
+			return err
+		}
+
+		for k, v := range value.(map[string]interface{}) {
+			if _, ok := result[k]; !ok {
+				result[k] = v
+			}
+		}
+
+		return nil
+	case yaml.SequenceNode:
+		for _, child := range n.Content {
+			if child.Kind == yaml.AliasNode {
+				child = child.Alias
+			}
+
+			if child.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d: merge value must be a mapping", child.Line)
+			}
+
+			err := c.merge(result, child)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("line %d: merge value must be a mapping", n.Line)
+	}
+}
@@ -0,0 +1,114 @@
+package yamltool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "block mapping & sequence",
+			doc:  "a: 1\nb:\n  - x\n  - true\n  - null\n",
+			want: map[string]interface{}{"a": 1.0, "b": []interface{}{"x", true, nil}},
+		},
+		{
+			name: "flow collections",
+			doc:  "a: {b: [1, 'c'], d: \"e\"}",
+			want: map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{1.0, "c"}, "d": "e"}},
+		},
+		{
+			name: "non string keys",
+			doc:  "responses:\n  200:\n    description: ok\n  default: {}\n",
+			want: map[string]interface{}{"responses": map[string]interface{}{"200": map[string]interface{}{"description": "ok"}, "default": map[string]interface{}{}}},
+		},
+		{
+			name: "block scalars",
+			doc:  "a: |\n  x\n  y\nb: >\n  x\n  y\n",
+			want: map[string]interface{}{"a": "x\ny\n", "b": "x y\n"},
+		},
+		{
+			name: "date is kept as string",
+			doc:  "a: 2024-01-01",
+			want: map[string]interface{}{"a": "2024-01-01"},
+		},
+		{
+			name: "empty document",
+			doc:  "",
+			want: nil,
+		},
+		{
+			name:    "stray flow mapping closer",
+			doc:     "a: [}]",
+			wantErr: true,
+		},
+		{
+			name:    "stray closer after flow scalar",
+			doc:     "a: [x}]",
+			wantErr: true,
+		},
+		{
+			name:    "unclosed flow sequence",
+			doc:     "a: [x, y",
+			wantErr: true,
+		},
+		{
+			name: "alias",
+			doc:  "a: &x {b: 1}\nc: *x\n",
+			want: map[string]interface{}{"a": map[string]interface{}{"b": 1.0}, "c": map[string]interface{}{"b": 1.0}},
+		},
+		{
+			name: "merge keys",
+			doc:  "base: &b {x: 1, y: 2}\nother: &o {y: 3, z: 4}\na:\n  <<: [*b, *o]\n  x: 5\n",
+			want: map[string]interface{}{
+				"base":  map[string]interface{}{"x": 1.0, "y": 2.0},
+				"other": map[string]interface{}{"y": 3.0, "z": 4.0},
+				"a":     map[string]interface{}{"x": 5.0, "y": 2.0, "z": 4.0},
+			},
+		},
+		{
+			name:    "merge of scalar",
+			doc:     "a:\n  <<: 1\n",
+			wantErr: true,
+		},
+		{
+			name:    "bad indentation",
+			doc:     "a:\n  b: 1\n c: 2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAliasBomb(t *testing.T) {
+	doc := `a: &a ["x", "x", "x", "x", "x", "x", "x", "x", "x"]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+f: &f [*e, *e, *e, *e, *e, *e, *e, *e, *e]
+g: &g [*f, *f, *f, *f, *f, *f, *f, *f, *f]
+h: &h [*g, *g, *g, *g, *g, *g, *g, *g, *g]
+i: &i [*h, *h, *h, *h, *h, *h, *h, *h, *h]
+`
+	if _, err := Parse(doc); err == nil {
+		t.Error("Parse() error = nil, want alias expansion error")
+	}
+}